@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrationFiles holds the SQL migrations that build the database schema.
+// Each file is named <version>_<name>.sql, e.g. 0001_initial_schema.sql, and is applied once, in order of version.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MIGRATION_LOCK_ID is the key of the advisory lock held while migrating,
+// so that servers starting at the same time do not apply the same migration twice.
+const MIGRATION_LOCK_ID = 20220110
+
+// A migration is a numbered change to the database schema.
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loadMigrations reads the migrations in the directory of the file system, ordered by version.
+// Returns an error if a file name does not start with a positive version, or if two files share a version.
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []migration{}
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix := strings.SplitN(name, "_", 2)[0]
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s must start with a positive version, e.g. 0001_name.sql", entry.Name())
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(b)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// migrate applies the migrations that have not yet been applied to the database, in order of version.
+// Each migration runs in its own transaction, along with the record of it in the schema_migrations table,
+// so that a failed migration leaves the database as it was before it.
+// Returns nil if the database is up to date, the error of the first migration to fail otherwise.
+func migrate(ctx context.Context, sqldb *sql.DB, migrations []migration) error {
+	// Hold the lock on a single connection for the whole migration
+	conn, err := sqldb.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1);`, MIGRATION_LOCK_ID); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1);`, MIGRATION_LOCK_ID)
+
+	sqlStmt := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name VARCHAR NOT NULL,
+		applied_on TIMESTAMPTZ NOT NULL
+	);
+	`
+	if _, err := conn.ExecContext(ctx, sqlStmt); err != nil {
+		return err
+	}
+
+	var current int
+	if err := conn.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations;`).Scan(&current); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return fmt.Errorf("migration %s failed: %v", m.name, err)
+		}
+		slog.Info("applied database migration", "migration", m.name)
+	}
+	return nil
+}
+
+// applyMigration runs a migration and records it in the schema_migrations table in a single transaction.
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_on) VALUES($1, $2, now());`, m.version, m.name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
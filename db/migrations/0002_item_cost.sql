@@ -0,0 +1,2 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS cost_cad FLOAT;
+ALTER TABLE deleted_items ADD COLUMN IF NOT EXISTS cost_cad FLOAT;
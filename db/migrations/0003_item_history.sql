@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS item_history (
+    id SERIAL PRIMARY KEY,
+    item_id CHAR(20) NOT NULL,
+    old_quantity INTEGER NOT NULL,
+    new_quantity INTEGER NOT NULL,
+    operation VARCHAR NOT NULL,
+    changed_on TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS item_history_item_id_idx ON item_history (item_id);
@@ -0,0 +1,2 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS reserved INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE deleted_items ADD COLUMN IF NOT EXISTS reserved INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS price_amount FLOAT, ADD COLUMN IF NOT EXISTS price_currency CHAR(3);
+UPDATE items SET price_amount = price_cad, price_currency = 'CAD' WHERE price_cad IS NOT NULL;
+ALTER TABLE items DROP COLUMN price_cad;
+
+ALTER TABLE deleted_items ADD COLUMN IF NOT EXISTS price_amount FLOAT, ADD COLUMN IF NOT EXISTS price_currency CHAR(3);
+UPDATE deleted_items SET price_amount = price_cad, price_currency = 'CAD' WHERE price_cad IS NOT NULL;
+ALTER TABLE deleted_items DROP COLUMN price_cad;
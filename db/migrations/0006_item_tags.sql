@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS item_tags (
+    item_id CHAR(20) NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    tag VARCHAR(32) NOT NULL,
+    PRIMARY KEY (item_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS item_tags_tag_idx ON item_tags (tag);
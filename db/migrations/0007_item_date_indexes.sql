@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS items_date_added_idx ON items (date_added);
+CREATE INDEX IF NOT EXISTS items_last_updated_idx ON items (last_updated);
@@ -0,0 +1,8 @@
+ALTER TABLE item_tags DROP CONSTRAINT item_tags_item_id_fkey;
+
+ALTER TABLE items ALTER COLUMN id TYPE VARCHAR(36);
+ALTER TABLE deleted_items ALTER COLUMN id TYPE VARCHAR(36);
+ALTER TABLE item_history ALTER COLUMN item_id TYPE VARCHAR(36);
+ALTER TABLE item_tags ALTER COLUMN item_id TYPE VARCHAR(36);
+
+ALTER TABLE item_tags ADD CONSTRAINT item_tags_item_id_fkey FOREIGN KEY (item_id) REFERENCES items (id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS barcode VARCHAR(13);
+ALTER TABLE deleted_items ADD COLUMN IF NOT EXISTS barcode VARCHAR(13);
+
+CREATE INDEX IF NOT EXISTS items_barcode_idx ON items (barcode);
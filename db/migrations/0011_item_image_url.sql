@@ -0,0 +1,2 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS image_url VARCHAR;
+ALTER TABLE deleted_items ADD COLUMN IF NOT EXISTS image_url VARCHAR;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS item_images (
+    item_id VARCHAR(36) NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    url VARCHAR NOT NULL,
+    PRIMARY KEY (item_id, position)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS supplier_name VARCHAR, ADD COLUMN IF NOT EXISTS supplier_sku VARCHAR;
+ALTER TABLE deleted_items ADD COLUMN IF NOT EXISTS supplier_name VARCHAR, ADD COLUMN IF NOT EXISTS supplier_sku VARCHAR;
+
+CREATE INDEX IF NOT EXISTS items_supplier_name_idx ON items (lower(supplier_name));
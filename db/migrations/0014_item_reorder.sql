@@ -0,0 +1,4 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS reorder_point INTEGER, ADD COLUMN IF NOT EXISTS reorder_quantity INTEGER;
+ALTER TABLE deleted_items ADD COLUMN IF NOT EXISTS reorder_point INTEGER, ADD COLUMN IF NOT EXISTS reorder_quantity INTEGER;
+
+CREATE INDEX IF NOT EXISTS items_reorder_idx ON items (id) WHERE quantity <= reorder_point;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS deleted_items_deleted_on_idx ON deleted_items (deleted_on);
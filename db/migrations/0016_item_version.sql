@@ -0,0 +1,2 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE deleted_items ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
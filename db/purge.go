@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DEFAULT_DELETED_RETENTION_DAYS is the number of days for which the records of deleted Items are kept by default.
+const DEFAULT_DELETED_RETENTION_DAYS = 90
+
+// PURGE_INTERVAL is how often PurgeDeleted removes the records of deleted Items that have outlived their retention.
+const PURGE_INTERVAL = time.Hour
+
+// DeletedRetention reads how long the records of deleted Items are kept from DELETED_RETENTION_DAYS,
+// in days, which is DEFAULT_DELETED_RETENTION_DAYS if unset.
+// Returns an error if the variable is set but is not a positive integer.
+func DeletedRetention() (time.Duration, error) {
+	days, err := envPositiveInt("DELETED_RETENTION_DAYS", DEFAULT_DELETED_RETENTION_DAYS)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// PurgeDeleted permanently removes the records of the Items deleted more than retention ago, once when it is called
+// and then every interval, so that the deleted_items table does not grow without bound.
+// The number of records removed in each cycle is logged, as is any error, which is retried in the next cycle.
+// It is meant to run in its own goroutine, and returns once the context is cancelled, e.g. when the server shuts down.
+func PurgeDeleted(ctx context.Context, db DB, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, _, err := db.PurgeDeletedBefore(ctx, time.Now().Add(-retention))
+		if err != nil && ctx.Err() == nil {
+			slog.Error("cannot purge deleted items", "error", err)
+		} else if err == nil {
+			slog.Info("purged deleted items", "count", n, "retention", retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RETRY_BACKOFF is how long to wait before retrying an operation that lost its connection to the database.
+// Each further retry waits twice as long as the one before.
+const RETRY_BACKOFF = 100 * time.Millisecond
+
+// CONNECT_BACKOFF is how long to wait before retrying the initial connection to the database.
+// Each further attempt waits twice as long as the one before, up to MAX_CONNECT_BACKOFF.
+const (
+	CONNECT_BACKOFF     = 250 * time.Millisecond
+	MAX_CONNECT_BACKOFF = 5 * time.Second
+)
+
+// connect pings the database until it responds, so that a server started alongside its database,
+// e.g. by docker-compose, survives the database's start-up rather than failing at once.
+// Pings that fail because the database cannot be reached are retried with exponential backoff
+// until the timeout has elapsed or the context is done; a timeout of 0 pings only once. Each retry is logged.
+// Returns the error of the last ping if the database never responds, or at once if the error is not a connection error.
+func connect(ctx context.Context, sqldb *sql.DB, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := CONNECT_BACKOFF
+	for attempt := 1; ; attempt++ {
+		err := sqldb.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 || !connectionLost(err) {
+			return err
+		}
+		if backoff > remaining {
+			backoff = remaining
+		}
+		slog.Warn("cannot connect to database; retrying", "attempt", attempt, "error", err, "backoff", backoff.Round(time.Millisecond))
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > MAX_CONNECT_BACKOFF {
+			backoff = MAX_CONNECT_BACKOFF
+		}
+	}
+}
+
+// retry calls op until it succeeds, fails with an error that is not retryable, or has been retried db.retries times,
+// backing off exponentially between attempts. It gives up early if the context is done.
+// Returns the error of the last attempt.
+func (db *SQLDB) retry(ctx context.Context, retryable func(err error) bool, op func() error) error {
+	backoff := RETRY_BACKOFF
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= db.retries || !retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// query runs a read-only query, retrying it if the connection to the database is lost.
+// The query is sent to the read replica, if there is one, as chosen by reader.
+func (db *SQLDB) query(ctx context.Context, sqlStmt string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := db.retry(ctx, connectionLost, func() error {
+		var err error
+		rows, err = db.reader(ctx).QueryContext(ctx, sqlStmt, args...)
+		return err
+	})
+	return rows, err
+}
+
+// queryRow runs a read-only query that returns at most one row and scans the row into dest,
+// retrying it if the connection to the database is lost.
+// The query is sent to the read replica, if there is one, as chosen by reader.
+// Returns sql.ErrNoRows if the query returns no rows.
+func (db *SQLDB) queryRow(ctx context.Context, sqlStmt string, args []interface{}, dest ...interface{}) error {
+	return db.retry(ctx, connectionLost, func() error {
+		return db.reader(ctx).QueryRowContext(ctx, sqlStmt, args...).Scan(dest...)
+	})
+}
+
+// exec runs a single statement that writes to the database.
+// The statement is only retried if it never reached the database, so that it is never applied twice.
+func (db *SQLDB) exec(ctx context.Context, sqlStmt string, args ...interface{}) (sql.Result, error) {
+	var res sql.Result
+	err := db.retry(ctx, neverSent, func() error {
+		var err error
+		res, err = db.db.ExecContext(ctx, sqlStmt, args...)
+		return err
+	})
+	return res, err
+}
+
+// inTx runs fn in a transaction and commits it.
+// If the connection to the database is lost before the commit, the transaction cannot have been applied,
+// so it is rolled back and retried from the start; fn must therefore be safe to call more than once.
+// A commit that loses its connection may or may not have been applied, so it is never retried.
+// Returns 0 and nil if the transaction was committed.
+// Returns the code and error of fn if it fails.
+// Returns a 500 Internal Server Error if the transaction cannot be started or committed.
+func (db *SQLDB) inTx(ctx context.Context, fn func(tx *sql.Tx) (int, error)) (int, error) {
+	code, committing := 0, false
+	err := db.retry(ctx, func(err error) bool { return !committing && connectionLost(err) }, func() error {
+		committing = false
+		tx, err := db.db.BeginTx(ctx, nil)
+		if err != nil {
+			code = http.StatusInternalServerError
+			return err
+		}
+		defer tx.Rollback()
+
+		if code, err = fn(tx); err != nil {
+			return err
+		}
+
+		committing = true
+		if err := tx.Commit(); err != nil {
+			code = http.StatusInternalServerError
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return code, err
+	}
+	return 0, nil
+}
+
+// connectionLost returns true if the error is caused by the connection to the database,
+// e.g. because the database is restarting, rather than by the statement itself, false otherwise.
+func connectionLost(err error) bool {
+	if neverSent(err) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Class 08 is connection exceptions; 57P01 and 57P02 are the server shutting down
+		return pqErr.Code.Class() == "08" || pqErr.Code == "57P01" || pqErr.Code == "57P02"
+	}
+	return false
+}
+
+// neverSent returns true if the error means that a statement could not have reached the database,
+// so that retrying it cannot apply it twice, false otherwise.
+func neverSent(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// 57P03 is the server not yet accepting connections; 08001 and 08004 are connections it refused
+		return pqErr.Code == "57P03" || pqErr.Code == "08001" || pqErr.Code == "08004"
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// POSTGRES_TEST_IMAGE is the image of the ephemeral PostgreSQL database that the SQL tests run against.
+// It matches the database of docker-compose.yml.
+const POSTGRES_TEST_IMAGE = "postgres:14.1-alpine3.15"
+
+var (
+	// testContainer is the PostgreSQL container started for the package's tests, or nil if none has been started.
+	testContainer testcontainers.Container
+
+	// testDatabaseURL is the connection string of the testContainer, or the empty string along with testDatabaseErr
+	// if it could not be started.
+	testDatabaseURL  string
+	testDatabaseErr  error
+	testDatabaseOnce sync.Once
+)
+
+// TestMain runs the package's tests and then stops the PostgreSQL container if any test started it,
+// so that the SQL tests leave nothing behind.
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if testContainer != nil {
+		testContainer.Terminate(context.Background())
+	}
+	os.Exit(code)
+}
+
+// startTestDatabase starts an ephemeral PostgreSQL database in a Docker container, once for all of the package's tests,
+// so that the SQL tests need nothing but Docker to run. The mock tests never start it.
+// Returns the database's connection string and nil if it is ready to accept connections, otherwise an error.
+func startTestDatabase() (string, error) {
+	testDatabaseOnce.Do(func() {
+		ctx := context.Background()
+		container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: testcontainers.ContainerRequest{
+				Image:        POSTGRES_TEST_IMAGE,
+				ExposedPorts: []string{"5432/tcp"},
+				Env: map[string]string{
+					"POSTGRES_DB":       "inventory_test",
+					"POSTGRES_USER":     "postgres",
+					"POSTGRES_PASSWORD": "postgres",
+				},
+				// PostgreSQL restarts once after initializing the database, so wait for it to be ready twice
+				WaitingFor: wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+			},
+			Started: true,
+		})
+		if err != nil {
+			testDatabaseErr = fmt.Errorf("cannot start test database: %v", err)
+			return
+		}
+		testContainer = container
+
+		host, err := container.Host(ctx)
+		if err != nil {
+			testDatabaseErr = fmt.Errorf("cannot find test database: %v", err)
+			return
+		}
+		port, err := container.MappedPort(ctx, "5432/tcp")
+		if err != nil {
+			testDatabaseErr = fmt.Errorf("cannot find test database: %v", err)
+			return
+		}
+		testDatabaseURL = fmt.Sprintf("postgres://postgres:postgres@%s:%s/inventory_test?sslmode=disable", host, port.Port())
+	})
+	return testDatabaseURL, testDatabaseErr
+}
+
+// newTestDB creates a reference to the PostgreSQL testing database, started by startTestDatabase,
+// brings its schema up to date, and removes all records to prepare it for a fresh test.
+// The test is skipped if the database cannot be started, e.g. if Docker is not available,
+// so that the mock tests can still be run on their own.
+// It assumes that the caller will also call Close to end the connection.
+// Returns a reference to the new DB and nil if the connection was successful,
+// otherwise returns a reference to an empty DB and an error.
+func newTestDB(t *testing.T) (*SQLDB, error) {
+	t.Helper()
+	db := &SQLDB{}
+	url, err := startTestDatabase()
+	if err != nil {
+		t.Skipf("skipping SQL test: %v", err)
+	}
+	if err := db.initDB(url); err != nil {
+		db.db = nil
+		return db, err
+	}
+	if err := db.clearTestDB(); err != nil {
+		db.db = nil
+		return db, err
+	}
+	return db, nil
+}
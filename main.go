@@ -1,43 +1,95 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/gorilla/mux"
 	"github.com/lbisceglia/shopify/db"
 	"github.com/lbisceglia/shopify/server"
 )
 
-const (
-	GET    = http.MethodGet
-	PUT    = http.MethodPut
-	POST   = http.MethodPost
-	DELETE = http.MethodDelete
+// version, commit, and built identify the build. They are injected at build time, e.g.
+// go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.built=$(date -u +%FT%TZ)"
+var (
+	version = "dev"
+	commit  = "unknown"
+	built   = "unknown"
 )
 
+// SHUTDOWN_TIMEOUT is how long the server waits for requests in flight to finish when it is shut down.
+const SHUTDOWN_TIMEOUT = 10 * time.Second
+
 func main() {
-	// Initialize Router
-	r := mux.NewRouter().StrictSlash(true)
+	// Log in the format and at the level configured by LOG_FORMAT and LOG_LEVEL
+	logger, err := server.NewLogger(os.Stderr)
+	if err != nil {
+		fatal(err)
+	}
+	slog.SetDefault(logger)
+
+	// Refuse to price items in the wrong currency
+	if _, err := server.DefaultCurrency(); err != nil {
+		fatal(err)
+	}
+
+	// Shut down on an interrupt or termination signal
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Initialize Database
-	db, err := db.NewSQLDB()
+	retention, err := db.DeletedRetention()
 	if err != nil {
-		log.Fatal(err)
-		return
+		fatal(err)
 	}
-	defer db.Close()
+	database, err := db.NewSQLDB()
+	if err != nil {
+		fatal(err)
+	}
+	defer database.Close()
+
+	// Purge old deleted items in the background until shutdown
+	var background sync.WaitGroup
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		db.PurgeDeleted(ctx, database, retention, db.PURGE_INTERVAL)
+	}()
 
 	// Initialize Server
-	s := server.NewServer(db)
+	server.Build = server.BuildInfo{Version: version, Commit: commit, Built: built}
+	s := server.NewServer(database)
 
 	// Routes and Handlers
-	r.HandleFunc("/api/items", s.CreateItem).Methods(POST)
-	r.HandleFunc("/api/items/{id}", s.UpdateItem).Methods(PUT)
-	r.HandleFunc("/api/items/{id}", s.DeleteItem).Methods(DELETE)
-	r.HandleFunc("/api/items", s.GetItems).Methods(GET)
-	r.HandleFunc("/api/items/{id}", s.GetItem).Methods(GET)
+	r := server.Routes(s)
 
 	// TODO: move port to environment var
-	log.Fatal(http.ListenAndServe(":8081", r))
+	srv := &http.Server{Addr: ":8081", Handler: r}
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		<-ctx.Done()
+		// Let requests in flight finish, for a while, before closing the database
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("cannot shut down server", "error", err)
+		}
+	}()
+	slog.Info("serving", "addr", srv.Addr, "version", version, "commit", commit)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		fatal(err)
+	}
+	background.Wait()
+}
+
+// fatal logs the error that stops the service from running and exits.
+func fatal(err error) {
+	slog.Error("cannot run server", "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// An ItemBatch requests several Items at once, by ID.
+type ItemBatch struct {
+	IDs []ID `json:"ids"`
+}
+
+// ValidateItemBatch checks that between 1 and BATCH_MAX_SIZE IDs are requested.
+// Returns a 400 Bad Request if there are no IDs or too many IDs.
+func (batch *ItemBatch) ValidateItemBatch() (int, error) {
+	return validateBatchIDs(batch.IDs)
+}
+
+// validateBatchIDs checks that between 1 and BATCH_MAX_SIZE IDs are requested.
+// Returns a 400 Bad Request if there are no IDs or too many IDs.
+func validateBatchIDs(ids []ID) (int, error) {
+	if len(ids) == 0 {
+		return http.StatusBadRequest, errors.New("ids are required")
+	}
+	if len(ids) > BATCH_MAX_SIZE {
+		return http.StatusBadRequest, fmt.Errorf("at most %d ids may be requested at once", BATCH_MAX_SIZE)
+	}
+	return 0, nil
+}
+
+// ParseIDs parses a comma-separated list of Item IDs, e.g. "a,b,c".
+// Blank and repeated IDs are ignored.
+// Returns the IDs, 0, and nil if successful.
+// Returns a 400 Bad Request if there are no IDs or too many IDs.
+func ParseIDs(list string) ([]ID, int, error) {
+	ids := []ID{}
+	seen := make(map[ID]bool)
+	for _, id := range strings.Split(list, ",") {
+		id := ID(strings.TrimSpace(id))
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if code, err := validateBatchIDs(ids); err != nil {
+		return nil, code, err
+	}
+	return ids, 0, nil
+}
+
+// A DeleteResult is the outcome of deleting a single Item in a batch.
+type DeleteResult string
+
+const (
+	DeleteResultDeleted  DeleteResult = "deleted"
+	DeleteResultNotFound DeleteResult = "not_found"
+)
+
+// NewDeleteResults describes the outcome of deleting each Item in a batch
+// from the status code of its deletion: 204 No Content if it was deleted, 404 Not Found if it did not exist.
+func NewDeleteResults(codes map[ID]int) map[ID]DeleteResult {
+	results := make(map[ID]DeleteResult, len(codes))
+	for id, code := range codes {
+		if code == http.StatusNoContent {
+			results[id] = DeleteResultDeleted
+		} else {
+			results[id] = DeleteResultNotFound
+		}
+	}
+	return results
+}
+
+// An ItemBatchResult holds the Items found for an ItemBatch, along with the IDs that were not found.
+type ItemBatchResult struct {
+	Items   []Item `json:"items"`
+	Missing []ID   `json:"missing"`
+}
+
+// NewItemBatchResult matches the Items found to the requested IDs.
+// The Items are ordered as their IDs were requested, and IDs requested more than once are only reported once.
+func NewItemBatchResult(ids []ID, items []Item) ItemBatchResult {
+	byID := make(map[ID]Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	result := ItemBatchResult{Items: []Item{}, Missing: []ID{}}
+	seen := make(map[ID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if item, ok := byID[id]; ok {
+			result.Items = append(result.Items, item)
+		} else {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+	return result
+}
+
+// ValidateBulkUpdate checks that between 1 and BATCH_MAX_SIZE Items are submitted for a bulk update,
+// that each has an ID and is well-formed in accordance with the API specification, as checked by ValidateItem,
+// and that no two share a SKU, as checked by ValidateBatchSKUs.
+// Returns a 400 Bad Request if there are no Items or too many Items, if any Item is malformed, or if any SKU is shared.
+func ValidateBulkUpdate(items []Item) (int, error) {
+	if len(items) == 0 {
+		return http.StatusBadRequest, errors.New("items are required")
+	}
+	if len(items) > BATCH_MAX_SIZE {
+		return http.StatusBadRequest, fmt.Errorf("at most %d items may be updated at once", BATCH_MAX_SIZE)
+	}
+	for i := range items {
+		items[i].ID = ID(strings.TrimSpace(string(items[i].ID)))
+		if items[i].ID == "" {
+			return http.StatusBadRequest, NewFieldError("id", "item %d: id is required", i)
+		}
+		if code, err := items[i].ValidateItem(); err != nil {
+			return code, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return ValidateBatchSKUs(items)
+}
+
+// ValidateBatchSKUs checks that no two Items in a batch share a SKU, before any is written.
+// SKUs are compared in their normalized form, as by NormalizeSKU, so that e.g. "abc-123" and "ABC-123" collide,
+// as they would once SKUs are normalized. The database would only reject such Items one by one, after the first is written.
+// It assumes that the SKUs have been validated with ValidateSKU.
+// Returns a 400 Bad Request naming each shared SKU and the Items it is given to, in the order the SKUs first appear.
+func ValidateBatchSKUs(items []Item) (int, error) {
+	indices := make(map[SKU][]int, len(items))
+	order := []SKU{}
+	for i, item := range items {
+		sku := NormalizeSKU(item.SKU)
+		if _, ok := indices[sku]; !ok {
+			order = append(order, sku)
+		}
+		indices[sku] = append(indices[sku], i)
+	}
+
+	duplicates := []string{}
+	for _, sku := range order {
+		if len(indices[sku]) < 2 {
+			continue
+		}
+		positions := make([]string, len(indices[sku]))
+		for i, index := range indices[sku] {
+			positions[i] = fmt.Sprint(index)
+		}
+		first := items[indices[sku][0]].SKU
+		duplicates = append(duplicates, fmt.Sprintf("%v (items %s)", first, strings.Join(positions, ", ")))
+	}
+	if len(duplicates) > 0 {
+		return http.StatusBadRequest, NewFieldError("sku", "SKUs cannot be given to more than one item: %s", strings.Join(duplicates, "; "))
+	}
+	return 0, nil
+}
+
+// An UpdateResult is the outcome of updating a single Item in a bulk update.
+// Status is the status code that updating the Item on its own would have had: 204 No Content if it was updated,
+// 404 Not Found if there was no Item with its ID, or 409 Conflict if its SKU, name, or barcode was already in use.
+// Error describes why the Item was not updated, and Field names the field at fault, if any; both are omitted if it was updated.
+// Change is the change that the update made to the Item's quantity, if it was updated; it is not part of the response.
+type UpdateResult struct {
+	ID     ID             `json:"id"`
+	Status int            `json:"status"`
+	Error  string         `json:"error,omitempty"`
+	Field  string         `json:"field,omitempty"`
+	Change QuantityChange `json:"-"`
+}
+
+// NewUpdateResult describes the outcome of updating the Item with the given ID from the status code and error of its update.
+func NewUpdateResult(id ID, code int, err error) UpdateResult {
+	result := UpdateResult{ID: id, Status: code}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		result.Field = fieldErr.Field
+	}
+	return result
+}
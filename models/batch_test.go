@@ -0,0 +1,179 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseIDs(t *testing.T) {
+	tooMany := make([]string, BATCH_MAX_SIZE+1)
+	for i := range tooMany {
+		tooMany[i] = string(NewID())
+	}
+
+	tests := map[string]struct {
+		list string
+		want []ID
+		code int
+	}{
+		"single id":              {list: "a", want: []ID{"a"}, code: 0},
+		"several ids":            {list: "a,b,c", want: []ID{"a", "b", "c"}, code: 0},
+		"blank and repeated ids": {list: " a, ,b,a,", want: []ID{"a", "b"}, code: 0},
+		"empty list":             {list: "", want: nil, code: http.StatusBadRequest},
+		"only blanks":            {list: " , ,", want: nil, code: http.StatusBadRequest},
+		"too many ids":           {list: strings.Join(tooMany, ","), want: nil, code: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, code, _ := ParseIDs(test.list)
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewItemBatchResult(t *testing.T) {
+	itemA := Item{ID: "00000000000000000001", SKU: "AAAAAAAA", Name: "Thing"}
+	itemB := Item{ID: "00000000000000000002", SKU: "BBBBBBBB", Name: "Thing"}
+
+	tests := map[string]struct {
+		ids   []ID
+		items []Item
+		want  ItemBatchResult
+	}{
+		"all found": {
+			ids:   []ID{itemA.ID, itemB.ID},
+			items: []Item{itemB, itemA},
+			want:  ItemBatchResult{Items: []Item{itemA, itemB}, Missing: []ID{}},
+		},
+		"none found": {
+			ids:   []ID{itemA.ID},
+			items: []Item{},
+			want:  ItemBatchResult{Items: []Item{}, Missing: []ID{itemA.ID}},
+		},
+		"some missing": {
+			ids:   []ID{itemB.ID, "00000000000000000003", itemA.ID},
+			items: []Item{itemA, itemB},
+			want:  ItemBatchResult{Items: []Item{itemB, itemA}, Missing: []ID{"00000000000000000003"}},
+		},
+		"duplicate ids": {
+			ids:   []ID{itemA.ID, "00000000000000000003", itemA.ID, "00000000000000000003"},
+			items: []Item{itemA},
+			want:  ItemBatchResult{Items: []Item{itemA}, Missing: []ID{"00000000000000000003"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := NewItemBatchResult(test.ids, test.items); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateBulkUpdate(t *testing.T) {
+	quantity := 1
+	negative := -1
+	tooMany := make([]Item, BATCH_MAX_SIZE+1)
+	for i := range tooMany {
+		tooMany[i] = Item{ID: NewID(), SKU: SKU(fmt.Sprintf("SKU%05d", i)), Name: "Thing", Quantity: &quantity}
+	}
+
+	tests := map[string]struct {
+		items []Item
+		code  int
+	}{
+		"valid":            {items: []Item{{ID: "00000000000000000001", SKU: "AAAAAAAA", Name: "Thing", Quantity: &quantity}}, code: 0},
+		"no items":         {items: []Item{}, code: http.StatusBadRequest},
+		"too many items":   {items: tooMany, code: http.StatusBadRequest},
+		"missing id":       {items: []Item{{SKU: "AAAAAAAA", Name: "Thing", Quantity: &quantity}}, code: http.StatusBadRequest},
+		"whitespace id":    {items: []Item{{ID: "  ", SKU: "AAAAAAAA", Name: "Thing", Quantity: &quantity}}, code: http.StatusBadRequest},
+		"invalid quantity": {items: []Item{{ID: "00000000000000000001", SKU: "AAAAAAAA", Name: "Thing", Quantity: &negative}}, code: http.StatusBadRequest},
+		"shared sku": {
+			items: []Item{{ID: "00000000000000000001", SKU: "AAAAAAAA", Name: "Thing", Quantity: &quantity}, {ID: "00000000000000000002", SKU: "aaaaaaaa", Name: "Thing", Quantity: &quantity}},
+			code:  http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := ValidateBulkUpdate(test.items)
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if isError := err != nil; isError != (test.code != 0) {
+				t.Errorf("got %v; want an error: %v", err, test.code != 0)
+			}
+		})
+	}
+}
+
+func TestNewUpdateResult(t *testing.T) {
+	tests := map[string]struct {
+		code int
+		err  error
+		want UpdateResult
+	}{
+		"updated":     {code: http.StatusNoContent, err: nil, want: UpdateResult{ID: "a", Status: http.StatusNoContent}},
+		"not found":   {code: http.StatusNotFound, err: errors.New("missing"), want: UpdateResult{ID: "a", Status: http.StatusNotFound, Error: "missing"}},
+		"field error": {code: http.StatusConflict, err: NewFieldError("sku", "taken"), want: UpdateResult{ID: "a", Status: http.StatusConflict, Error: "taken", Field: "sku"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := NewUpdateResult("a", test.code, test.err); got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateBatchSKUs(t *testing.T) {
+	tests := map[string]struct {
+		skus []SKU
+		code int
+		want string
+	}{
+		"unique":          {skus: []SKU{"AAAAAAAA", "BBBBBBBB"}, code: 0},
+		"shared":          {skus: []SKU{"AAAAAAAA", "BBBBBBBB", "AAAAAAAA"}, code: http.StatusBadRequest, want: "SKUs cannot be given to more than one item: AAAAAAAA (items 0, 2)"},
+		"shared any case": {skus: []SKU{"abc-123", "ABC-123"}, code: http.StatusBadRequest, want: "SKUs cannot be given to more than one item: abc-123 (items 0, 1)"},
+		"several shared": {
+			skus: []SKU{"BBBBBBBB", "AAAAAAAA", "AAAAAAAA", "BBBBBBBB", "BBBBBBBB"},
+			code: http.StatusBadRequest,
+			want: "SKUs cannot be given to more than one item: BBBBBBBB (items 0, 3, 4); AAAAAAAA (items 1, 2)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			items := make([]Item, len(test.skus))
+			for i, sku := range test.skus {
+				items[i] = Item{SKU: sku}
+			}
+			code, err := ValidateBatchSKUs(items)
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if test.code == 0 {
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Field != "sku" {
+				t.Fatalf("got %v; want an error on sku", err)
+			}
+			if got := err.Error(); got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A Cursor marks a position in the collection of Items, ordered by date added and then by ID,
+// so that a client can resume listing after the last Item it has seen.
+// Unlike an offset, a Cursor does not skip or repeat Items when others are added or deleted mid-scan.
+type Cursor struct {
+	DateAdded time.Time
+	ID        ID
+}
+
+// CursorOf returns the Cursor positioned at the given Item.
+// An Item without a date added is positioned at the zero time.
+func CursorOf(item *Item) Cursor {
+	cursor := Cursor{ID: item.ID}
+	if item.DateAdded != nil {
+		cursor.DateAdded = *item.DateAdded
+	}
+	return cursor
+}
+
+// After returns true if the Item comes after the Cursor, false otherwise.
+func (c Cursor) After(item *Item) bool {
+	other := CursorOf(item)
+	if !other.DateAdded.Equal(c.DateAdded) {
+		return other.DateAdded.After(c.DateAdded)
+	}
+	return other.ID > c.ID
+}
+
+// String encodes the Cursor as an opaque, URL-safe string.
+func (c Cursor) String() string {
+	raw := c.DateAdded.UTC().Format(time.RFC3339Nano) + "," + string(c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a Cursor from the string returned by Cursor.String.
+// Returns the Cursor, 0, and nil if successful.
+// Returns a 400 Bad Request if the string is not a valid Cursor.
+func ParseCursor(s string) (Cursor, int, error) {
+	invalid := errors.New("after must be a cursor returned by a previous request")
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, http.StatusBadRequest, invalid
+	}
+	parts := strings.SplitN(string(raw), ",", 2)
+	if len(parts) != 2 {
+		return Cursor{}, http.StatusBadRequest, invalid
+	}
+	dateAdded, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Cursor{}, http.StatusBadRequest, invalid
+	}
+	id := ID(parts[1])
+	if _, err := id.isValid(); err != nil {
+		return Cursor{}, http.StatusBadRequest, invalid
+	}
+	return Cursor{DateAdded: dateAdded, ID: id}, 0, nil
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	added := time.Date(2022, time.January, 10, 18, 38, 38, 123456000, time.UTC)
+
+	tests := map[string]Cursor{
+		"cursor":    {DateAdded: added, ID: "0123456789abcdefghij"},
+		"zero time": {ID: "0123456789abcdefghij"},
+	}
+
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, code, err := ParseCursor(cursor.String())
+			if err != nil {
+				t.Fatalf("got %v; want %v", code, 0)
+			}
+			if !got.DateAdded.Equal(cursor.DateAdded) || got.ID != cursor.ID {
+				t.Errorf("got %v; want %v", got, cursor)
+			}
+		})
+	}
+}
+
+func TestParseCursorInvalid(t *testing.T) {
+	tests := map[string]string{
+		"not base64":     "!!!",
+		"no separator":   Cursor{}.String()[:4],
+		"malformed time": "bm90LWEtdGltZSwwMTIzNDU2Nzg5YWJjZGVmZ2hpag",
+		"malformed id":   Cursor{ID: "not-an-id"}.String(),
+	}
+
+	for name, s := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, code, err := ParseCursor(s)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got, want := code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCursorAfter(t *testing.T) {
+	t1 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	cursor := Cursor{DateAdded: t1, ID: "00000000000000000002"}
+
+	tests := map[string]struct {
+		item Item
+		want bool
+	}{
+		"same date, greater id": {item: Item{ID: "00000000000000000003", DateAdded: &t1}, want: true},
+		"same date, same id":    {item: Item{ID: "00000000000000000002", DateAdded: &t1}, want: false},
+		"same date, lesser id":  {item: Item{ID: "00000000000000000001", DateAdded: &t1}, want: false},
+		"later date, lesser id": {item: Item{ID: "00000000000000000001", DateAdded: &t2}, want: true},
+		"no date":               {item: Item{ID: "00000000000000000009"}, want: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, want := cursor.After(&test.item), test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+import "fmt"
+
+// A FieldError is an error caused by the value of a single field of an Item, e.g. a SKU that is already in use.
+// Field is the name of the field as it appears in JSON, so that clients can tell errors on different fields apart.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+// NewFieldError creates a FieldError on the given field with a formatted message.
+func NewFieldError(field string, format string, a ...interface{}) *FieldError {
+	return &FieldError{Field: field, Err: fmt.Errorf(format, a...)}
+}
+
+// Error returns the message of the underlying error.
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// An EventType is a kind of change made to an Item that is reported to the webhook.
+type EventType string
+
+const (
+	EventItemCreated  EventType = "item.created"
+	EventItemUpdated  EventType = "item.updated"
+	EventItemDeleted  EventType = "item.deleted"
+	EventItemAdjusted EventType = "item.adjusted"
+	EventItemLowStock EventType = "item.low_stock"
+)
+
+// An Event reports a change made to an Item.
+// Item holds the state of the Item after the change; it is omitted if the Item was deleted.
+type Event struct {
+	Type EventType `json:"type"`
+	ID   ID        `json:"id"`
+	Item *Item     `json:"item,omitempty"`
+	At   time.Time `json:"at"`
+}
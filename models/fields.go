@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ItemFields holds the names of the Item fields that a client may project, as they appear in JSON.
+var ItemFields = []string{"id", "sku", "name", "description", "price", "cost_CAD", "quantity", "reserved", "available", "tags", "barcode", "image_url", "images", "margin", "supplier", "reorder_point", "reorder_quantity", "version"}
+
+// ParseFields parses a comma-separated list of Item field names, e.g. "id,name,price".
+// Blank and repeated names are ignored.
+// Returns the field names, 0, and nil if successful, or nil if the list is empty.
+// Returns a 400 Bad Request if a field name is not one of the ItemFields.
+func ParseFields(list string) ([]string, int, error) {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !isItemField(field) {
+			return nil, http.StatusBadRequest, fmt.Errorf("unknown field %q", field)
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, 0, nil
+}
+
+// isItemField returns true if the name is one of the ItemFields, false otherwise.
+func isItemField(name string) bool {
+	for _, field := range ItemFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Project returns the JSON representation of the Item restricted to the given fields.
+// Optional fields that are not present on the Item are omitted, as they are from a whole Item.
+func (item *Item) Project(fields []string) (map[string]interface{}, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[string]interface{})
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+
+	projection := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if v, ok := all[field]; ok {
+			projection[field] = v
+		}
+	}
+	return projection, nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type FieldsResult struct {
+	list string
+	want []string
+	code int
+}
+
+func TestParseFields(t *testing.T) {
+	tests := map[string]FieldsResult{
+		"empty": {
+			list: "",
+			want: nil,
+			code: 0,
+		},
+		"single field": {
+			list: "name",
+			want: []string{"name"},
+			code: 0,
+		},
+		"several fields": {
+			list: "id, name,price",
+			want: []string{"id", "name", "price"},
+			code: 0,
+		},
+		"repeated field": {
+			list: "name,name",
+			want: []string{"name"},
+			code: 0,
+		},
+		"blank fields": {
+			list: ",name,,",
+			want: []string{"name"},
+			code: 0,
+		},
+		"unknown field": {
+			list: "name,colour",
+			want: nil,
+			code: http.StatusBadRequest,
+		},
+		"wrong case": {
+			list: "Name",
+			want: nil,
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, code, _ := ParseFields(test.list)
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	testQuantity := 2
+	item := Item{
+		ID:       "00000000000000000001",
+		SKU:      "AAAAAAAA",
+		Name:     "Thing1",
+		Quantity: &testQuantity,
+	}
+
+	got, err := item.Project([]string{"sku", "quantity", "description"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"sku": "AAAAAAAA", "quantity": 2.0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
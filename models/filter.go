@@ -0,0 +1,153 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// A Filter restricts which Items are returned when listing inventory.
+// The zero Filter matches every Item.
+type Filter struct {
+	// MinValue, if present, matches Items whose stock value (price * quantity) is at least MinValue.
+	// Stock value is in the DefaultCurrency, so Items without a price in it never match.
+	MinValue *float64
+
+	// MinPrice and MaxPrice, if present, match Items whose price is within [MinPrice, MaxPrice].
+	// Prices are in the DefaultCurrency, so Items priced in another currency never match. Items without a price
+	// do not match a MinPrice, but are not excluded by a MaxPrice alone.
+	MinPrice *float64
+	MaxPrice *float64
+
+	// InStock, if present, matches Items with stock available to sell (Quantity - Reserved > 0) if true,
+	// or Items without any if false.
+	InStock *bool
+
+	// Tags, if present, matches Items that have every one of the Tags.
+	Tags []string
+
+	// Supplier, if present, matches Items supplied by the supplier of that name, ignoring case.
+	Supplier string
+
+	// NeedsReorder, if true, matches Items whose stock has fallen to their reorder point.
+	NeedsReorder bool
+
+	// AddedAfter and AddedBefore, if present, match Items added within [AddedAfter, AddedBefore).
+	AddedAfter  *time.Time
+	AddedBefore *time.Time
+
+	// UpdatedAfter and UpdatedBefore, if present, match Items last updated within [UpdatedAfter, UpdatedBefore).
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+
+	// DeletedAfter and DeletedBefore, if present, match deleted Items that were deleted within [DeletedAfter, DeletedBefore).
+	// Items in inventory have never been deleted, so they never match either bound.
+	DeletedAfter  *time.Time
+	DeletedBefore *time.Time
+
+	// IncludeDeleted matches deleted Items as well as those in inventory, and OnlyDeleted matches only deleted Items.
+	// By default, deleted Items never match.
+	IncludeDeleted bool
+	OnlyDeleted    bool
+
+	// Limit, if positive, selects a page of at most Limit of the matching Items, after skipping the first Offset.
+	// Pages are ordered by date added, then by ID. Limit and Offset do not restrict which Items match,
+	// so Matches ignores them.
+	Limit  int
+	Offset int
+}
+
+// A TagCount holds the number of Items that have a tag.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Matches returns true if the Item satisfies every condition of the Filter, false otherwise.
+func (f *Filter) Matches(item *Item) bool {
+	if deleted := item.DeletedAt != nil; deleted && !f.IncludeDeleted && !f.OnlyDeleted || !deleted && f.OnlyDeleted {
+		return false
+	}
+	if f.MinValue != nil {
+		value, ok := item.StockValue()
+		if !ok || value < *f.MinValue {
+			return false
+		}
+	}
+	if !item.priceInRange(f.MinPrice, f.MaxPrice) {
+		return false
+	}
+	if f.InStock != nil && item.InStock() != *f.InStock {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !item.HasTag(tag) {
+			return false
+		}
+	}
+	if f.Supplier != "" && (item.Supplier == nil || !strings.EqualFold(item.Supplier.Name, f.Supplier)) {
+		return false
+	}
+	if f.NeedsReorder && !item.NeedsReorder() {
+		return false
+	}
+	return inRange(item.DateAdded, f.AddedAfter, f.AddedBefore) &&
+		inRange(item.LastUpdated, f.UpdatedAfter, f.UpdatedBefore) &&
+		inRange(item.DeletedAt, f.DeletedAfter, f.DeletedBefore)
+}
+
+// inRange returns true if t is within [after, before), false otherwise.
+// A missing bound is unbounded. A missing t is only within an unbounded range.
+func inRange(t, after, before *time.Time) bool {
+	if after == nil && before == nil {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	return (after == nil || !t.Before(*after)) && (before == nil || t.Before(*before))
+}
+
+// priceInRange returns true if the Item's price in the DefaultCurrency is within [min, max], false otherwise.
+// A missing bound is unbounded. An Item without a price is only within a range without a minimum,
+// and an Item priced in another currency is only within an unbounded range.
+func (item *Item) priceInRange(min, max *float64) bool {
+	if min == nil && max == nil {
+		return true
+	}
+	if item.Price == nil {
+		return min == nil
+	}
+	if item.Price.Currency != DefaultCurrency {
+		return false
+	}
+	return (min == nil || item.Price.Amount >= *min) && (max == nil || item.Price.Amount <= *max)
+}
+
+// InStock returns true if the Item has stock available to sell (Quantity - Reserved > 0), false otherwise.
+func (item *Item) InStock() bool {
+	return item.Quantity != nil && *item.Quantity-item.Reserved > 0
+}
+
+// NeedsReorder returns true if the Item has a ReorderPoint and its Quantity is at or below it, false otherwise.
+func (item *Item) NeedsReorder() bool {
+	return item.ReorderPoint != nil && item.Quantity != nil && *item.Quantity <= *item.ReorderPoint
+}
+
+// HasTag returns true if the Item has the tag, false otherwise.
+func (item *Item) HasTag(tag string) bool {
+	for _, t := range item.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// StockValue returns the value in the DefaultCurrency of an Item's stock (Price * Quantity).
+// Returns the value and true if the Item has a Price in the DefaultCurrency, 0 and false otherwise.
+func (item *Item) StockValue() (float64, bool) {
+	if item.Price == nil || item.Price.Currency != DefaultCurrency || item.Quantity == nil {
+		return 0, false
+	}
+	return item.Price.Amount * float64(*item.Quantity), true
+}
@@ -0,0 +1,254 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+type FilterResult struct {
+	filter Filter
+	item   Item
+	want   bool
+}
+
+func TestFilterMatches(t *testing.T) {
+	minValue := 100.0
+	minPrice, maxPrice := 20.0, 30.0
+	lowPrice := Price{Amount: 10.0, Currency: "CAD"}
+	inStock, outOfStock := true, false
+	testQuantityNone := 0
+	testPrice := Price{Amount: 25.0, Currency: "CAD"}
+	testPriceUSD := Price{Amount: 25.0, Currency: "USD"}
+	testQuantityAbove := 5
+	testQuantityExact := 4
+	testQuantityBelow := 3
+	jan1 := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	jan2 := time.Date(2022, time.January, 2, 0, 0, 0, 0, time.UTC)
+	jan3 := time.Date(2022, time.January, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]FilterResult{
+		"empty filter": {
+			filter: Filter{},
+			item:   Item{Quantity: &testQuantityBelow},
+			want:   true,
+		},
+		"value above threshold": {
+			filter: Filter{MinValue: &minValue},
+			item:   Item{Price: &testPrice, Quantity: &testQuantityAbove},
+			want:   true,
+		},
+		"value at threshold": {
+			filter: Filter{MinValue: &minValue},
+			item:   Item{Price: &testPrice, Quantity: &testQuantityExact},
+			want:   true,
+		},
+		"value below threshold": {
+			filter: Filter{MinValue: &minValue},
+			item:   Item{Price: &testPrice, Quantity: &testQuantityBelow},
+			want:   false,
+		},
+		"unpriced": {
+			filter: Filter{MinValue: &minValue},
+			item:   Item{Quantity: &testQuantityAbove},
+			want:   false,
+		},
+		"price within range": {
+			filter: Filter{MinPrice: &minPrice, MaxPrice: &maxPrice},
+			item:   Item{Price: &testPrice},
+			want:   true,
+		},
+		"price below range": {
+			filter: Filter{MinPrice: &minPrice, MaxPrice: &maxPrice},
+			item:   Item{Price: &lowPrice},
+			want:   false,
+		},
+		"price above maximum": {
+			filter: Filter{MaxPrice: &minPrice},
+			item:   Item{Price: &testPrice},
+			want:   false,
+		},
+		"price at bounds": {
+			filter: Filter{MinPrice: &testPrice.Amount, MaxPrice: &testPrice.Amount},
+			item:   Item{Price: &testPrice},
+			want:   true,
+		},
+		"unpriced with minimum price": {
+			filter: Filter{MinPrice: &minPrice},
+			item:   Item{},
+			want:   false,
+		},
+		"unpriced with maximum price": {
+			filter: Filter{MaxPrice: &maxPrice},
+			item:   Item{},
+			want:   true,
+		},
+		"price in another currency": {
+			filter: Filter{MaxPrice: &maxPrice},
+			item:   Item{Price: &testPriceUSD},
+			want:   false,
+		},
+		"in stock": {
+			filter: Filter{InStock: &inStock},
+			item:   Item{Quantity: &testQuantityBelow},
+			want:   true,
+		},
+		"in stock but all reserved": {
+			filter: Filter{InStock: &inStock},
+			item:   Item{Quantity: &testQuantityBelow, Reserved: testQuantityBelow},
+			want:   false,
+		},
+		"out of stock": {
+			filter: Filter{InStock: &outOfStock},
+			item:   Item{Quantity: &testQuantityNone},
+			want:   true,
+		},
+		"out of stock but has stock": {
+			filter: Filter{InStock: &outOfStock},
+			item:   Item{Quantity: &testQuantityBelow},
+			want:   false,
+		},
+		"has tag": {
+			filter: Filter{Tags: []string{"electronics"}},
+			item:   Item{Tags: []string{"audio", "electronics"}},
+			want:   true,
+		},
+		"has every tag": {
+			filter: Filter{Tags: []string{"electronics", "audio"}},
+			item:   Item{Tags: []string{"audio", "electronics"}},
+			want:   true,
+		},
+		"missing a tag": {
+			filter: Filter{Tags: []string{"electronics", "video"}},
+			item:   Item{Tags: []string{"audio", "electronics"}},
+			want:   false,
+		},
+		"no tags": {
+			filter: Filter{Tags: []string{"electronics"}},
+			item:   Item{},
+			want:   false,
+		},
+		"same supplier": {
+			filter: Filter{Supplier: "acme"},
+			item:   Item{Supplier: &Supplier{Name: "Acme"}},
+			want:   true,
+		},
+		"other supplier": {
+			filter: Filter{Supplier: "Acme"},
+			item:   Item{Supplier: &Supplier{Name: "Acme Industries"}},
+			want:   false,
+		},
+		"no supplier": {
+			filter: Filter{Supplier: "Acme"},
+			item:   Item{},
+			want:   false,
+		},
+		"below reorder point": {
+			filter: Filter{NeedsReorder: true},
+			item:   Item{Quantity: &testQuantityBelow, ReorderPoint: &testQuantityExact},
+			want:   true,
+		},
+		"at reorder point": {
+			filter: Filter{NeedsReorder: true},
+			item:   Item{Quantity: &testQuantityExact, ReorderPoint: &testQuantityExact},
+			want:   true,
+		},
+		"above reorder point": {
+			filter: Filter{NeedsReorder: true},
+			item:   Item{Quantity: &testQuantityAbove, ReorderPoint: &testQuantityExact},
+			want:   false,
+		},
+		"no reorder point": {
+			filter: Filter{NeedsReorder: true},
+			item:   Item{Quantity: &testQuantityNone},
+			want:   false,
+		},
+		"priced in another currency": {
+			filter: Filter{MinValue: &minValue},
+			item:   Item{Price: &testPriceUSD, Quantity: &testQuantityAbove},
+			want:   false,
+		},
+		"added within range": {
+			filter: Filter{AddedAfter: &jan1, AddedBefore: &jan3},
+			item:   Item{DateAdded: &jan2},
+			want:   true,
+		},
+		"added at start of range": {
+			filter: Filter{AddedAfter: &jan2},
+			item:   Item{DateAdded: &jan2},
+			want:   true,
+		},
+		"added at end of range": {
+			filter: Filter{AddedBefore: &jan2},
+			item:   Item{DateAdded: &jan2},
+			want:   false,
+		},
+		"added before range": {
+			filter: Filter{AddedAfter: &jan2},
+			item:   Item{DateAdded: &jan1},
+			want:   false,
+		},
+		"updated after range": {
+			filter: Filter{UpdatedBefore: &jan2},
+			item:   Item{DateAdded: &jan1, LastUpdated: &jan3},
+			want:   false,
+		},
+		"updated within range": {
+			filter: Filter{UpdatedAfter: &jan2},
+			item:   Item{DateAdded: &jan1, LastUpdated: &jan3},
+			want:   true,
+		},
+		"no dates": {
+			filter: Filter{UpdatedAfter: &jan2},
+			item:   Item{},
+			want:   false,
+		},
+		"deleted": {
+			filter: Filter{},
+			item:   Item{DeletedAt: &jan1},
+			want:   false,
+		},
+		"deleted with deleted included": {
+			filter: Filter{IncludeDeleted: true},
+			item:   Item{DeletedAt: &jan1},
+			want:   true,
+		},
+		"not deleted with deleted included": {
+			filter: Filter{IncludeDeleted: true},
+			item:   Item{},
+			want:   true,
+		},
+		"deleted only": {
+			filter: Filter{OnlyDeleted: true},
+			item:   Item{DeletedAt: &jan1},
+			want:   true,
+		},
+		"not deleted with deleted only": {
+			filter: Filter{OnlyDeleted: true},
+			item:   Item{},
+			want:   false,
+		},
+		"deleted within range": {
+			filter: Filter{OnlyDeleted: true, DeletedAfter: &jan1, DeletedBefore: &jan3},
+			item:   Item{DeletedAt: &jan2},
+			want:   true,
+		},
+		"deleted after range": {
+			filter: Filter{OnlyDeleted: true, DeletedBefore: &jan2},
+			item:   Item{DeletedAt: &jan3},
+			want:   false,
+		},
+		"not deleted with deleted range": {
+			filter: Filter{IncludeDeleted: true, DeletedAfter: &jan1},
+			item:   Item{DateAdded: &jan2},
+			want:   false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.filter.Matches(&test.item); got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// EnforceReservedStock, if true, rejects changes to an Item's stock that would leave more stock
+// reserved than is in inventory, i.e. a negative available stock.
+// It is off by default so that quantities may still be corrected below outstanding reservations.
+var EnforceReservedStock = false
+
+// BATCH_MAX_SIZE is the maximum number of Items that may be requested in a single batch.
+const BATCH_MAX_SIZE = 100
+
+// An Operation is a kind of change made to an Item's quantity.
+type Operation string
+
+const (
+	OperationCreate    Operation = "create"
+	OperationUpdate    Operation = "update"
+	OperationAdjust    Operation = "adjust"
+	OperationStocktake Operation = "stocktake"
+	OperationTransfer  Operation = "transfer"
+)
+
+// A HistoryEntry records a single change made to an Item's quantity.
+type HistoryEntry struct {
+	ItemID      ID        `json:"item_id"`
+	OldQuantity int       `json:"old_quantity"`
+	NewQuantity int       `json:"new_quantity"`
+	Operation   Operation `json:"operation"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// A QuantityChange records an Item's quantity before and after a single write, as read from the Item's row
+// while it was locked for the write, so that the change can be reported exactly even while other writes are under way.
+type QuantityChange struct {
+	ID          ID
+	OldQuantity int
+	NewQuantity int
+}
+
+// CrossedReorderPoint returns true if the change brought the quantity down to the reorder point from above it, false otherwise.
+// A change that left the quantity above the reorder point, or that started at or below it, has not crossed it,
+// nor has any change to an Item without a reorder point.
+func (change QuantityChange) CrossedReorderPoint(reorderPoint *int) bool {
+	return reorderPoint != nil && change.NewQuantity <= *reorderPoint && change.OldQuantity > *reorderPoint
+}
+
+// An Adjustment is a relative change to an Item's stock.
+// When adjusting quantity, a positive Amount adds stock and a negative Amount removes it.
+// When reserving or releasing stock, the Amount must be positive.
+type Adjustment struct {
+	Amount *int `json:"amount"`
+}
+
+// ValidateAdjustment checks that the Amount is present.
+// Returns a 400 Bad Request if the Amount is missing.
+func (adj *Adjustment) ValidateAdjustment() (int, error) {
+	if adj.Amount == nil {
+		return http.StatusBadRequest, errors.New("amount is required")
+	}
+	return 0, nil
+}
+
+// ValidateReservation checks that the Amount is present and positive.
+// Returns a 400 Bad Request if the Amount is missing or not positive.
+func (adj *Adjustment) ValidateReservation() (int, error) {
+	if code, err := adj.ValidateAdjustment(); err != nil {
+		return code, err
+	}
+	if *adj.Amount <= 0 {
+		return http.StatusBadRequest, errors.New("amount must be positive")
+	}
+	return 0, nil
+}
+
+// A Transfer moves an Amount of stock from one Item to another, e.g. from a part to the kit assembled from it.
+type Transfer struct {
+	From   ID   `json:"from"`
+	To     ID   `json:"to"`
+	Amount *int `json:"amount"`
+}
+
+// ValidateTransfer checks that both Items are given and are different, and that the Amount is present and positive.
+// Returns a 400 Bad Request if the Transfer is malformed.
+func (transfer *Transfer) ValidateTransfer() (int, error) {
+	if transfer.From == "" || transfer.To == "" {
+		return http.StatusBadRequest, errors.New("from and to are required")
+	}
+	if transfer.From == transfer.To {
+		return http.StatusBadRequest, errors.New("from and to must be different items")
+	}
+	adj := Adjustment{Amount: transfer.Amount}
+	return adj.ValidateReservation()
+}
+
+// CheckReserved checks that the Item with the given ID would still have enough stock to cover its
+// reservations with the given quantity, if EnforceReservedStock is set.
+// Reserving and releasing stock always keeps the reserved stock within the quantity.
+// Returns a 409 Conflict if the reserved stock would exceed the quantity.
+func CheckReserved(id ID, quantity, reserved int) (int, error) {
+	if EnforceReservedStock && reserved > quantity {
+		return http.StatusConflict, fmt.Errorf("cannot set quantity of item with ID %v to %d; %d units are reserved", id, quantity, reserved)
+	}
+	return 0, nil
+}
+
+// A HistoryBatch requests the history of several Items at once.
+type HistoryBatch struct {
+	IDs []ID `json:"ids"`
+}
+
+// ValidateHistoryBatch checks that between 1 and BATCH_MAX_SIZE IDs are requested.
+// Returns a 400 Bad Request if there are no IDs or too many IDs.
+func (batch *HistoryBatch) ValidateHistoryBatch() (int, error) {
+	return validateBatchIDs(batch.IDs)
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+)
+
+type CheckReservedResult struct {
+	enforce  bool
+	quantity int
+	reserved int
+	code     int
+}
+
+func TestCheckReserved(t *testing.T) {
+	tests := map[string]CheckReservedResult{
+		"not enforced above quantity": {
+			enforce:  false,
+			quantity: 2,
+			reserved: 3,
+			code:     0,
+		},
+		"enforced below quantity": {
+			enforce:  true,
+			quantity: 5,
+			reserved: 3,
+			code:     0,
+		},
+		"enforced at quantity": {
+			enforce:  true,
+			quantity: 3,
+			reserved: 3,
+			code:     0,
+		},
+		"enforced above quantity": {
+			enforce:  true,
+			quantity: 2,
+			reserved: 3,
+			code:     http.StatusConflict,
+		},
+	}
+
+	defer func() { EnforceReservedStock = false }()
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			EnforceReservedStock = test.enforce
+			code, _ := CheckReserved("00000000000000000001", test.quantity, test.reserved)
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestValidateTransfer(t *testing.T) {
+	five, zero := 5, 0
+
+	tests := map[string]struct {
+		transfer Transfer
+		code     int
+	}{
+		"valid":          {transfer: Transfer{From: "00000000000000000001", To: "00000000000000000002", Amount: &five}, code: 0},
+		"missing from":   {transfer: Transfer{To: "00000000000000000002", Amount: &five}, code: http.StatusBadRequest},
+		"missing to":     {transfer: Transfer{From: "00000000000000000001", Amount: &five}, code: http.StatusBadRequest},
+		"same item":      {transfer: Transfer{From: "00000000000000000001", To: "00000000000000000001", Amount: &five}, code: http.StatusBadRequest},
+		"missing amount": {transfer: Transfer{From: "00000000000000000001", To: "00000000000000000002"}, code: http.StatusBadRequest},
+		"zero amount":    {transfer: Transfer{From: "00000000000000000001", To: "00000000000000000002", Amount: &zero}, code: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, _ := test.transfer.ValidateTransfer()
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestQuantityChangeCrossedReorderPoint(t *testing.T) {
+	two, five := 2, 5
+
+	tests := map[string]struct {
+		change       QuantityChange
+		reorderPoint *int
+		want         bool
+	}{
+		"crossed":                  {change: QuantityChange{OldQuantity: 6, NewQuantity: 2}, reorderPoint: &five, want: true},
+		"crossed to reorder point": {change: QuantityChange{OldQuantity: 6, NewQuantity: 5}, reorderPoint: &five, want: true},
+		"already below":            {change: QuantityChange{OldQuantity: 3, NewQuantity: 2}, reorderPoint: &five, want: false},
+		"already at reorder point": {change: QuantityChange{OldQuantity: 5, NewQuantity: 2}, reorderPoint: &five, want: false},
+		"unchanged":                {change: QuantityChange{OldQuantity: 2, NewQuantity: 2}, reorderPoint: &five, want: false},
+		"still above":              {change: QuantityChange{OldQuantity: 6, NewQuantity: 5}, reorderPoint: &two, want: false},
+		"rose above":               {change: QuantityChange{OldQuantity: 1, NewQuantity: 5}, reorderPoint: &two, want: false},
+		"no reorder point":         {change: QuantityChange{OldQuantity: 6, NewQuantity: 2}, want: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.change.CrossedReorderPoint(test.reorderPoint); got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
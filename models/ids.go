@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rs/xid"
+)
+
+// An IDGenerator creates new, globally-unique IDs for Items.
+type IDGenerator interface {
+	NewID() ID
+}
+
+// An XIDGenerator creates IDs from xids, which sort by the time they were created.
+type XIDGenerator struct{}
+
+// NewID creates a new, globally-unique ID from an xid.
+func (XIDGenerator) NewID() ID {
+	return ID(xid.New().String())
+}
+
+// A UUIDGenerator creates IDs from random (version 4) UUIDs.
+type UUIDGenerator struct{}
+
+// NewID creates a new, globally-unique ID from a random UUID.
+func (UUIDGenerator) NewID() ID {
+	return ID(uuid.New().String())
+}
+
+// A DefaultIDGenerator creates IDs with NewID, in the ItemIDFormat in effect when each ID is created.
+// It is the default IDGenerator.
+type DefaultIDGenerator struct{}
+
+// NewID creates a new, globally-unique ID in the ItemIDFormat.
+func (DefaultIDGenerator) NewID() ID {
+	return NewID()
+}
+
+// A SequenceIDGenerator creates predictable IDs in the xid format from a counter, e.g. "00000000000000000001".
+// It is designed for testing purposes, so that tests can assert exact IDs, and should not be used in production.
+// It is safe for concurrent use.
+type SequenceIDGenerator struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewSequenceIDGenerator creates a SequenceIDGenerator whose first ID is the given seed.
+func NewSequenceIDGenerator(seed uint64) *SequenceIDGenerator {
+	return &SequenceIDGenerator{next: seed}
+}
+
+// NewID creates the next ID in the sequence, as a zero-padded decimal number.
+func (g *SequenceIDGenerator) NewID() ID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := ID(fmt.Sprintf("%0*d", ID_LEN, g.next))
+	g.next++
+	return id
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestIDGenerators(t *testing.T) {
+	tests := map[string]struct {
+		ids IDGenerator
+	}{
+		"xid":      {ids: XIDGenerator{}},
+		"sequence": {ids: NewSequenceIDGenerator(1)},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			first, second := test.ids.NewID(), test.ids.NewID()
+			for _, id := range []ID{first, second} {
+				if _, err := id.isValid(); err != nil {
+					t.Errorf("got %v; want a valid ID", err)
+				}
+			}
+			if first == second {
+				t.Errorf("got %v twice; want unique IDs", first)
+			}
+		})
+	}
+}
+
+func TestSequenceIDGenerator(t *testing.T) {
+	ids := NewSequenceIDGenerator(9)
+
+	for _, want := range []ID{"00000000000000000009", "00000000000000000010", "00000000000000000011"} {
+		if got := ids.NewID(); got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}
+}
@@ -4,46 +4,159 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
-	"github.com/rs/xid"
+	"github.com/google/uuid"
 )
 
 const (
 	SKU_MIN_LEN = 4
 	SKU_MAX_LEN = 12
 	ID_LEN      = 20 // tied to xid specification
+	UUID_LEN    = 36 // canonical form, e.g. "f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	TAG_MIN_LEN = 1
+	TAG_MAX_LEN = 32
+	EAN13_LEN   = 13
+	MAX_IMAGES  = 10
+
+	NAME_MAX_LEN        = 255
+	DESCRIPTION_MAX_LEN = 4096
+
+	SUPPLIER_NAME_MAX_LEN = 255
+	SUPPLIER_SKU_MAX_LEN  = 64
+)
+
+// RequireAlphanumericSKU, if true, rejects SKUs that do not contain at least one letter or digit, e.g. "--------".
+// It is off by default so that existing SKUs made up only of hyphens and underscores remain valid.
+var RequireAlphanumericSKU = false
+
+// StrictQuantity, if true, rejects Items without a Quantity rather than giving them a Quantity of 0.
+// It is off by default so that clients that omit the Quantity of new Items are not affected.
+var StrictQuantity = false
+
+// UniqueNames, if true, rejects Items whose Name is already in use by another Item, as is always the case for SKUs.
+// It is off by default so that stores that allow duplicate names are not affected.
+var UniqueNames = false
+
+// UniqueBarcodes, if true, rejects Items whose Barcode is already in use by another Item.
+// It is off by default so that variants sold under a manufacturer's single barcode are not affected.
+var UniqueBarcodes = false
+
+// SKUMinLen and SKUMaxLen bound the length of SKUs, e.g. 13 for stores keyed by EAN-13 barcodes.
+// They are SKU_MIN_LEN and SKU_MAX_LEN by default. SKUMinLen must not exceed SKUMaxLen.
+var (
+	SKUMinLen = SKU_MIN_LEN
+	SKUMaxLen = SKU_MAX_LEN
+)
+
+// NameMaxLen and DescriptionMaxLen bound the length of Names and Descriptions, in characters.
+// They are NAME_MAX_LEN and DESCRIPTION_MAX_LEN by default.
+var (
+	NameMaxLen        = NAME_MAX_LEN
+	DescriptionMaxLen = DESCRIPTION_MAX_LEN
+)
+
+// UppercaseSKU, if true, canonicalizes SKUs to uppercase on input, e.g. "abc-123" is stored as "ABC-123".
+// It is off by default; run the SKU normalization preview before enabling it on an existing store.
+var UppercaseSKU = false
+
+// An IDFormat is a format of Item IDs.
+type IDFormat string
+
+const (
+	XID_FORMAT  IDFormat = "xid"
+	UUID_FORMAT IDFormat = "uuid"
 )
 
+// ItemIDFormat is the format in which new IDs are created and all IDs are validated.
+// It is XID_FORMAT by default; UUID_FORMAT suits stores whose Items are keyed by an upstream system's UUIDs.
+var ItemIDFormat = XID_FORMAT
+
+// ParseIDFormat parses the name of an IDFormat, ignoring case.
+// Returns XID_FORMAT if the name is empty, or an error if it is not a known IDFormat.
+func ParseIDFormat(name string) (IDFormat, error) {
+	switch format := IDFormat(strings.ToLower(strings.TrimSpace(name))); format {
+	case "":
+		return XID_FORMAT, nil
+	case XID_FORMAT, UUID_FORMAT:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown ID format %q; must be %q or %q", name, XID_FORMAT, UUID_FORMAT)
+}
+
 // An ID is a globally-unique identifier for an Item.
 // It is allocated for indexing purposes and for use with a database.
 // IDs are immutable. An Item maintains the same ID throughout its life.
-// It must be 20 characters long and contain only the lowercase letters a-v and digits 0-9.
+// In the default xid format, it must be 20 characters long and contain only the lowercase letters a-v and digits 0-9.
+// In the UUID format, it must be a UUID in its canonical, lowercase form.
 type ID string
 
-// NewID creates a new, globally-unique ID.
+// NewID creates a new, globally-unique ID in the ItemIDFormat.
 func NewID() ID {
-	return ID(xid.New().String())
+	if ItemIDFormat == UUID_FORMAT {
+		return UUIDGenerator{}.NewID()
+	}
+	return XIDGenerator{}.NewID()
 }
 
 // A SKU is a unique identifier for an Item.
 // It is more human-friendly than ID and is allocated for internal use.
 // An Item's SKU may be updated over its life, but must always remain unique.
-// It may be 4 to 12 characters in length and contain only alphanumeric characters, hyphens, or underscores.
+// It may be SKUMinLen to SKUMaxLen (4 to 12 by default) characters in length and contain only alphanumeric characters, hyphens, or underscores.
 type SKU string
 
 // An Item holds data about an inventory item.
 type Item struct {
-	ID          ID         `json:"id"`
-	SKU         SKU        `json:"sku"`
-	Name        string     `json:"name"`
-	Description string     `json:"description,omitempty"`
-	PriceInCAD  *float64   `json:"price_CAD,omitempty"`
-	Quantity    *int       `json:"quantity"`
-	DateAdded   *time.Time `json:"-"`
-	LastUpdated *time.Time `json:"-"`
+	ID              ID          `json:"id"`
+	SKU             SKU         `json:"sku"`
+	Name            string      `json:"name"`
+	Description     string      `json:"description,omitempty"`
+	Price           *Price      `json:"price,omitempty"`
+	PriceInCAD      *float64    `json:"price_CAD,omitempty"` // Deprecated: only accepted on input, see ValidatePrice
+	CostInCAD       *float64    `json:"cost_CAD,omitempty"`
+	Quantity        *int        `json:"quantity"`
+	Reserved        int         `json:"reserved"`
+	Available       int         `json:"available"`
+	UnitMargin      *UnitMargin `json:"margin,omitempty"`
+	Tags            []string    `json:"tags,omitempty"`
+	Barcode         string      `json:"barcode,omitempty"`
+	ImageURL        string      `json:"image_url,omitempty"`
+	Images          []string    `json:"images,omitempty"`
+	Supplier        *Supplier   `json:"supplier,omitempty"`
+	ReorderPoint    *int        `json:"reorder_point,omitempty"`
+	ReorderQuantity *int        `json:"reorder_quantity,omitempty"`
+	Version         int         `json:"version,omitempty"` // Starts at 1 and counts every change; in a request, the version expected, if any
+	DateAdded       *time.Time  `json:"-"`
+	LastUpdated     *time.Time  `json:"-"`
+	DeletedAt       *time.Time  `json:"deleted_at,omitempty"` // Only set on Items listed along with deleted Items
+}
+
+// A Supplier identifies who supplies an Item, for reordering.
+// Name is the supplier's name and SKU is the supplier's own SKU for the Item, if known.
+type Supplier struct {
+	Name string `json:"name"`
+	SKU  string `json:"sku,omitempty"`
+}
+
+// A CreatedItem holds a newly-created Item along with its server-assigned timestamps,
+// which are otherwise not part of an Item's json representation.
+type CreatedItem struct {
+	*Item
+	DateAdded   *time.Time `json:"date_added,omitempty"`
+	LastUpdated *time.Time `json:"last_updated,omitempty"`
+}
+
+// NewCreatedItem wraps a newly-created Item so that its timestamps are included in its json representation.
+func NewCreatedItem(item *Item) CreatedItem {
+	return CreatedItem{
+		Item:        item,
+		DateAdded:   item.DateAdded,
+		LastUpdated: item.LastUpdated,
+	}
 }
 
 // GetID returns an item's id field.
@@ -71,48 +184,271 @@ func (item *Item) ValidateID() (int, error) {
 }
 
 // ValidateSKU checks that the SKU is present and formatted according to the API specifcations.
+// Any leading or trailing whitespace is trimmed first, and the SKU is uppercased if UppercaseSKU is set.
 // Returns a 400 Bad Request if the SKU is invalid.
 func (item *Item) ValidateSKU() (int, error) {
+	item.SKU = SKU(strings.TrimSpace(string(item.SKU)))
+	if len(item.SKU) == 0 {
+		return http.StatusBadRequest, errors.New("SKU cannot be whitespace or empty")
+	}
+	if UppercaseSKU {
+		item.SKU = NormalizeSKU(item.SKU)
+	}
 	return item.SKU.isValid()
 }
 
 // ValidateName checks that the Name is present and formatted according to the API specifications.
-// Names are properly formatted if they contain at least 1 non-whitespace character.
-// Returns a 400 Bad Request if the SKU is invalid.
+// Names have any leading or trailing whitespace trimmed and are properly formatted
+// if they contain at least 1 non-whitespace character and are at most NameMaxLen characters long.
+// Returns a 400 Bad Request if the Name is invalid.
 func (item *Item) ValidateName() (int, error) {
 	item.Name = strings.TrimSpace(item.Name)
 	if len(item.Name) == 0 {
 		return http.StatusBadRequest, errors.New("name cannot be whitespace or empty")
 	}
+	if utf8.RuneCountInString(item.Name) > NameMaxLen {
+		return http.StatusBadRequest, NewFieldError("name", "name cannot be longer than %d characters", NameMaxLen)
+	}
 	return 0, nil
 }
 
-// ValidateDescription formats the Description according to the API specification.
-// Descriptions are properly formatted if any leading or trailing whitespace is trimmed.
-// Returns nil as there are no restrictions on Descriptions.
+// ValidateDescription checks that the Description is formatted according to the API specification.
+// Descriptions have any leading or trailing whitespace trimmed and are properly formatted
+// if they are at most DescriptionMaxLen characters long. Characters are counted, not bytes,
+// so that descriptions in other scripts are not penalized.
+// Returns a 400 Bad Request if the Description is too long.
 func (item *Item) ValidateDescription() (int, error) {
 	item.Description = strings.TrimSpace(item.Description)
+	if utf8.RuneCountInString(item.Description) > DescriptionMaxLen {
+		return http.StatusBadRequest, fmt.Errorf("description cannot be longer than %d characters", DescriptionMaxLen)
+	}
 	return 0, nil
 }
 
-// ValidatePrice checks that the PriceInCAD is formatted according to the API specifications, if it is present.
-// PriceInCAD is an optional field.
-// If PriceInCAD is present, it is properly formatted if it is non-negative.
-// Returns a 400 Bad Request if the PriceInCAD is invalid.
+// ValidatePrice checks that the Price is formatted according to the API specifications, if it is present.
+// Price is an optional field.
+// For backward compatibility, a PriceInCAD may be given instead of a Price; it is mapped to a Price in CAD
+// and cleared, so that Items are only ever written with a Price.
+// If Price is present, it is properly formatted if its amount is non-negative, its currency is a known ISO-4217 code,
+// and its amount has no more decimal places than the currency allows, e.g. 2 for CAD and 0 for JPY.
+// Returns a 400 Bad Request if the Price is invalid or if both Price and PriceInCAD are present.
 func (item *Item) ValidatePrice() (int, error) {
-	if price := item.PriceInCAD; price != nil && *price < 0 {
-		return http.StatusBadRequest, errors.New("price_CAD cannot be negative")
+	if item.PriceInCAD != nil {
+		if item.Price != nil {
+			return http.StatusBadRequest, errors.New("price and price_CAD cannot both be present")
+		}
+		if *item.PriceInCAD < 0 {
+			return http.StatusBadRequest, errors.New("price_CAD cannot be negative")
+		}
+		item.Price = &Price{Amount: *item.PriceInCAD, Currency: LEGACY_CURRENCY}
+		item.PriceInCAD = nil
+	}
+	if item.Price != nil {
+		return item.Price.isValid()
+	}
+	return 0, nil
+}
+
+// ValidateCost checks that the CostInCAD is formatted according to the API specifications, if it is present.
+// CostInCAD is an optional field.
+// If CostInCAD is present, it is properly formatted if it is non-negative.
+// Returns a 400 Bad Request if the CostInCAD is invalid.
+func (item *Item) ValidateCost() (int, error) {
+	if cost := item.CostInCAD; cost != nil && *cost < 0 {
+		return http.StatusBadRequest, errors.New("cost_CAD cannot be negative")
+	}
+	return 0, nil
+}
+
+// ValidateTags checks that the Tags are formatted according to the API specifications, if they are present.
+// Tags is an optional field.
+// Each tag has any leading or trailing whitespace trimmed, and duplicate tags are removed.
+// Tags are properly formatted if each is between 1 and 32 characters long.
+// Returns a 400 Bad Request if any tag is invalid.
+func (item *Item) ValidateTags() (int, error) {
+	if item.Tags == nil {
+		return 0, nil
+	}
+
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range item.Tags {
+		tag = strings.TrimSpace(tag)
+		if len := utf8.RuneCountInString(tag); len < TAG_MIN_LEN || len > TAG_MAX_LEN {
+			return http.StatusBadRequest, fmt.Errorf("tags must be between %d and %d characters in length", TAG_MIN_LEN, TAG_MAX_LEN)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	item.Tags = tags
+	return 0, nil
+}
+
+// ValidateBarcode checks that the Barcode is formatted according to the API specifications, if it is present.
+// Barcode is an optional field and has any leading or trailing whitespace trimmed.
+// If Barcode is present, it is properly formatted if it is a valid EAN-13, as checked by ValidateEAN13.
+// Returns a 400 Bad Request if the Barcode is invalid.
+func (item *Item) ValidateBarcode() (int, error) {
+	item.Barcode = strings.TrimSpace(item.Barcode)
+	if item.Barcode == "" {
+		return 0, nil
+	}
+	return ValidateEAN13(item.Barcode)
+}
+
+// ValidateEAN13 checks that the code is a valid EAN-13 barcode.
+// EAN-13s are properly formatted if they are 13 digits long and the last digit is the checksum of the first 12,
+// i.e. the digits, weighted alternately by 1 and 3, sum to a multiple of 10.
+// Returns a 400 Bad Request if the code is invalid.
+func ValidateEAN13(code string) (int, error) {
+	if len(code) != EAN13_LEN {
+		return http.StatusBadRequest, fmt.Errorf("barcode must be %d digits in length", EAN13_LEN)
+	}
+	sum := 0
+	for i, c := range code {
+		if c < '0' || c > '9' {
+			return http.StatusBadRequest, errors.New("barcode may only contain [0-9]")
+		}
+		digit := int(c - '0')
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	if sum%10 != 0 {
+		return http.StatusBadRequest, errors.New("barcode has an invalid check digit")
+	}
+	return 0, nil
+}
+
+// ValidateImageURL checks that the ImageURL is formatted according to the API specifications, if it is present.
+// ImageURL is an optional field and has any leading or trailing whitespace trimmed.
+// If ImageURL is present, it is properly formatted if it is an absolute http or https URL with a host,
+// e.g. "https://cdn.example.com/thing.png".
+// Returns a 400 Bad Request if the ImageURL is invalid.
+func (item *Item) ValidateImageURL() (int, error) {
+	item.ImageURL = strings.TrimSpace(item.ImageURL)
+	if item.ImageURL == "" {
+		return 0, nil
+	}
+	if err := validateHTTPURL(item.ImageURL); err != nil {
+		return http.StatusBadRequest, fmt.Errorf("image_url %v", err)
 	}
 	return 0, nil
 }
 
+// ValidateImages checks that the Images are formatted according to the API specifications, if they are present.
+// Images is an optional field, an ordered gallery of at most MAX_IMAGES images whose order is preserved.
+// Each image has any leading or trailing whitespace trimmed and is properly formatted
+// if it is an absolute http or https URL with a host, as for ImageURL.
+// Returns a 400 Bad Request if there are too many images or any image is invalid, in which case none are accepted.
+func (item *Item) ValidateImages() (int, error) {
+	if item.Images == nil {
+		return 0, nil
+	}
+	if len(item.Images) > MAX_IMAGES {
+		return http.StatusBadRequest, fmt.Errorf("images cannot contain more than %d images", MAX_IMAGES)
+	}
+
+	images := make([]string, len(item.Images))
+	for i, image := range item.Images {
+		images[i] = strings.TrimSpace(image)
+		if err := validateHTTPURL(images[i]); err != nil {
+			return http.StatusBadRequest, fmt.Errorf("images[%d] %v", i, err)
+		}
+	}
+	item.Images = images
+	return 0, nil
+}
+
+// ValidateSupplier checks that the Supplier is formatted according to the API specifications, if it is present.
+// Supplier is an optional field. Its Name and SKU have any leading or trailing whitespace trimmed.
+// If Supplier is present, it is properly formatted if its Name is 1 to SUPPLIER_NAME_MAX_LEN characters long
+// and its optional SKU is at most SUPPLIER_SKU_MAX_LEN characters long.
+// Returns a 400 Bad Request if the Supplier is invalid.
+func (item *Item) ValidateSupplier() (int, error) {
+	if item.Supplier == nil {
+		return 0, nil
+	}
+	item.Supplier.Name = strings.TrimSpace(item.Supplier.Name)
+	item.Supplier.SKU = strings.TrimSpace(item.Supplier.SKU)
+	if len := utf8.RuneCountInString(item.Supplier.Name); len == 0 || len > SUPPLIER_NAME_MAX_LEN {
+		return http.StatusBadRequest, NewFieldError("supplier", "supplier name must be between 1 and %d characters in length", SUPPLIER_NAME_MAX_LEN)
+	}
+	if utf8.RuneCountInString(item.Supplier.SKU) > SUPPLIER_SKU_MAX_LEN {
+		return http.StatusBadRequest, NewFieldError("supplier", "supplier SKU cannot be longer than %d characters", SUPPLIER_SKU_MAX_LEN)
+	}
+	return 0, nil
+}
+
+// validateHTTPURL checks that the raw URL is an absolute http or https URL with a host.
+// Returns an error describing the problem if it is not.
+func validateHTTPURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.New("must be a well-formed URL")
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return errors.New("must be an absolute URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("must be an http or https URL")
+	}
+	return nil
+}
+
+// ValidateReorder checks that the ReorderPoint and ReorderQuantity are formatted according to the API specifications,
+// if they are present. Both are optional fields.
+// If present, they are properly formatted if they are non-negative.
+// Returns a 400 Bad Request if either is invalid.
+func (item *Item) ValidateReorder() (int, error) {
+	if p := item.ReorderPoint; p != nil && *p < 0 {
+		return http.StatusBadRequest, NewFieldError("reorder_point", "reorder_point cannot be negative")
+	}
+	if q := item.ReorderQuantity; q != nil && *q < 0 {
+		return http.StatusBadRequest, NewFieldError("reorder_quantity", "reorder_quantity cannot be negative")
+	}
+	return 0, nil
+}
+
+// ValidateVersion checks that the Version is formatted according to the API specifications, if it is present.
+// Version is an optional field; if present, it is the version that the client expects the Item to be at,
+// and it is properly formatted if it is positive, as Items start at version 1. A Version of 0 is missing.
+// Returns a 400 Bad Request if the Version is invalid.
+func (item *Item) ValidateVersion() (int, error) {
+	if item.Version < 0 {
+		return http.StatusBadRequest, NewFieldError("version", "version must be positive")
+	}
+	return 0, nil
+}
+
+// CheckVersion checks that an Item at the current version may be written by a request that expects the given version,
+// as in an optimistic update. Any version may be written if none is expected (0).
+// Returns a 409 Conflict if the Item is no longer at the expected version.
+func CheckVersion(id ID, current, expected int) (int, error) {
+	if expected != 0 && current != expected {
+		return http.StatusConflict, VersionConflict(id, expected)
+	}
+	return 0, nil
+}
+
+// VersionConflict returns the error of a write to the Item with the given ID that expected it to be at a version it is no longer at.
+func VersionConflict(id ID, expected int) error {
+	return NewFieldError("version", "item with ID %v is no longer at version %d", id, expected)
+}
+
 // ValidateQuantity checks that the Quantity is formatted according to the API specifications, if it is present.
-// Quantity is an optional field and will take on a default value of 0 if it is not provided.
+// Quantity is an optional field and will take on a default value of 0 if it is not provided, unless StrictQuantity is set,
+// in which case it is required. A Quantity of 0 is always present, so only an omitted or null Quantity is missing.
 // If Quantity is present, it is properly formatted if it is non-negative.
-// Returns a 400 Bad Request if the Quantity is invalid.
+// Returns a 400 Bad Request if the Quantity is invalid, or if it is missing and StrictQuantity is set.
 func (item *Item) ValidateQuantity() (int, error) {
 	if qty := item.Quantity; qty != nil && *qty < 0 {
 		return http.StatusBadRequest, errors.New("quantity cannot be negative")
+	} else if qty == nil && StrictQuantity {
+		return http.StatusBadRequest, NewFieldError("quantity", "quantity is required")
 	} else if qty == nil {
 		q := 0
 		item.Quantity = &q
@@ -120,10 +456,17 @@ func (item *Item) ValidateQuantity() (int, error) {
 	return 0, nil
 }
 
-// isValid checks that the ID is present and formatted according to the API specifcations.
-// IDs are properly formatted if they are 20 characters long and contain only lowercase letters a-v and numerical digits 0-9.
+// isValid checks that the ID is present and formatted according to the API specifcations and the ItemIDFormat.
+// xids are properly formatted if they are 20 characters long and contain only lowercase letters a-v and numerical digits 0-9.
+// UUIDs are properly formatted if they are in their canonical, lowercase form.
 // Returns a 400 Bad Request if the ID is invalid.
 func (id ID) isValid() (int, error) {
+	if ItemIDFormat == UUID_FORMAT {
+		if u, err := uuid.Parse(string(id)); err != nil || u.String() != string(id) {
+			return http.StatusBadRequest, errors.New("id must be a lowercase UUID, e.g. f47ac10b-58cc-4372-a567-0e02b2c3d479")
+		}
+		return 0, nil
+	}
 	if len(id) != ID_LEN {
 		return http.StatusBadRequest, fmt.Errorf("id must be %d characters in length", ID_LEN)
 	}
@@ -136,24 +479,32 @@ func (id ID) isValid() (int, error) {
 }
 
 // isValid checks that the SKU is present and formatted according to the API specifcations.
-// SKUs are properly formatted if they are between 4 and 12 characters long and contain only alphanumeric characters, hyphens, or underscores.
+// SKUs are properly formatted if they are between SKUMinLen and SKUMaxLen characters long and contain only alphanumeric characters, hyphens, or underscores.
+// If RequireAlphanumericSKU is set, SKUs must also contain at least one alphanumeric character.
 // Returns a 400 Bad Request if the SKU is invalid.
 func (sku SKU) isValid() (int, error) {
-	if len := len(sku); len < SKU_MIN_LEN || len > SKU_MAX_LEN {
-		return http.StatusBadRequest, fmt.Errorf("SKU must be between %d and %d characters in length", SKU_MIN_LEN, SKU_MAX_LEN)
+	if len := len(sku); len < SKUMinLen || len > SKUMaxLen {
+		return http.StatusBadRequest, fmt.Errorf("SKU must be between %d and %d characters in length", SKUMinLen, SKUMaxLen)
 	}
+	alphanumeric := false
 	for _, c := range sku {
-		if !(unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_') {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			alphanumeric = true
+		} else if c != '-' && c != '_' {
 			return http.StatusBadRequest, fmt.Errorf("SKU may only contain [a-z A-Z 0-9 _ -]")
 		}
 	}
+	if RequireAlphanumericSKU && !alphanumeric {
+		return http.StatusBadRequest, errors.New("SKU must contain at least one alphanumeric character")
+	}
 	return 0, nil
 }
 
 // ValidateItem ensures that all properties needed to write the Item to database are present and properly formatted.
 // SKU and Name are mandatory as they can never be empty.
-// Description, PriceInCAD and Quantity may be empty, but will be overwritten to their default values:
-// empty string, nil, 0, respectively.
+// Description, Price, CostInCAD, Quantity, Tags, Barcode, ImageURL, Images, Supplier, ReorderPoint and ReorderQuantity
+// may be empty, but will be overwritten to their default values:
+// empty string, nil, nil, 0, nil, empty string, empty string, nil, nil, nil, nil, respectively.
 // Returns a 400 Bad Request for invalid Items.
 func (item *Item) ValidateItem() (int, error) {
 	if code, err := item.ValidateSKU(); err != nil {
@@ -164,13 +515,49 @@ func (item *Item) ValidateItem() (int, error) {
 		return code, err
 	} else if code, err = item.ValidatePrice(); err != nil {
 		return code, err
+	} else if code, err = item.ValidateCost(); err != nil {
+		return code, err
 	} else if code, err = item.ValidateQuantity(); err != nil {
 		return code, err
+	} else if code, err = item.ValidateTags(); err != nil {
+		return code, err
+	} else if code, err = item.ValidateBarcode(); err != nil {
+		return code, err
+	} else if code, err = item.ValidateImageURL(); err != nil {
+		return code, err
+	} else if code, err = item.ValidateImages(); err != nil {
+		return code, err
+	} else if code, err = item.ValidateSupplier(); err != nil {
+		return code, err
+	} else if code, err = item.ValidateReorder(); err != nil {
+		return code, err
+	} else if code, err = item.ValidateVersion(); err != nil {
+		return code, err
 	}
 	return 0, nil
 }
 
+// ComputeDerived sets the fields of the Item that are computed by the server rather than stored:
+// Available, by ComputeAvailable, and UnitMargin, by ComputeMargin.
+// It is only meaningful on Items read from the database.
+func (item *Item) ComputeDerived() {
+	item.ComputeAvailable()
+	item.ComputeMargin()
+}
+
+// ComputeAvailable sets Available to the stock that is not held by a reservation (Quantity - Reserved).
+// It is managed by the server and is only meaningful on Items read from the database.
+func (item *Item) ComputeAvailable() {
+	item.Available = -item.Reserved
+	if item.Quantity != nil {
+		item.Available += *item.Quantity
+	}
+}
+
 // IdIsPresent returns true if the ID property is present in the Item, false otherwise.
 func (item *Item) IdIsPresent() bool {
+	if ItemIDFormat == UUID_FORMAT {
+		return len(item.ID) == UUID_LEN
+	}
 	return len(item.ID) == ID_LEN
 }
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -145,55 +148,790 @@ func TestValidateID(t *testing.T) {
 	}
 }
 
+func TestValidateUUID(t *testing.T) {
+	ItemIDFormat = UUID_FORMAT
+	defer func() { ItemIDFormat = XID_FORMAT }()
+
+	tests := map[string]ValidateResult{
+		"valid uuid": {
+			item:    Item{ID: "f47ac10b-58cc-4372-a567-0e02b2c3d479"},
+			code:    0,
+			isError: false,
+		},
+		"invalid xid": {
+			item:    Item{ID: "abcdefghijklmnopqrst"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid uppercase uuid": {
+			item:    Item{ID: "F47AC10B-58CC-4372-A567-0E02B2C3D479"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid uuid without hyphens": {
+			item:    Item{ID: "f47ac10b58cc4372a5670e02b2c3d479"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid braced uuid": {
+			item:    Item{ID: "{f47ac10b-58cc-4372-a567-0e02b2c3d479}"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid no id": {
+			item:    Item{},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateID()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestIDFormats(t *testing.T) {
+	defer func() { ItemIDFormat = XID_FORMAT }()
+
+	for _, format := range []IDFormat{XID_FORMAT, UUID_FORMAT} {
+		t.Run(string(format), func(t *testing.T) {
+			ItemIDFormat = format
+
+			item := Item{}
+			if err := item.SetID(NewID()); err != nil {
+				t.Fatal(err)
+			}
+			if !item.IdIsPresent() {
+				t.Errorf("got %v; want an ID present", item.ID)
+			}
+		})
+	}
+}
+
+func TestParseIDFormat(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		want    IDFormat
+		isError bool
+	}{
+		"default": {name: "", want: XID_FORMAT, isError: false},
+		"xid":     {name: "xid", want: XID_FORMAT, isError: false},
+		"uuid":    {name: " UUID", want: UUID_FORMAT, isError: false},
+		"unknown": {name: "ulid", want: "", isError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseIDFormat(test.name)
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
 func TestValidateSKU(t *testing.T) {
 	tests := map[string]ValidateResult{
-		"invalid no sku": {
+		"invalid no sku": {
+			item:    Item{},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid whitespace sku": {
+			item:    Item{SKU: "    \t"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid short sku": {
+			item:    Item{SKU: "ABC"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid short sku with spaces": {
+			item:    Item{SKU: "  ABC  "},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"valid sku with spaces": {
+			item:    Item{SKU: "  ABCDefgh\t"},
+			code:    0,
+			isError: false,
+		},
+		"valid sku minimal": {
+			item:    Item{SKU: "A_-0"},
+			code:    0,
+			isError: false,
+		},
+		"valid sku only letters": {
+			item:    Item{SKU: "ABCDefgh"},
+			code:    0,
+			isError: false,
+		},
+		"valid sku only numbers": {
+			item:    Item{SKU: "01234567"},
+			code:    0,
+			isError: false,
+		},
+		"valid sku only hyphens": {
+			item:    Item{SKU: "--------"},
+			code:    0,
+			isError: false,
+		},
+		"valid sku only underscores": {
+			item:    Item{SKU: "________"},
+			code:    0,
+			isError: false,
+		},
+		"valid id maximal": {
+			item:    Item{SKU: "Ab_0-12_Cd-3"},
+			code:    0,
+			isError: false,
+		},
+		"invalid character": {
+			item:    Item{SKU: "AAAAAAA?"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid long id": {
+			item:    Item{SKU: "Ab_0-12345678"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateSKU()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestValidateSKURequireAlphanumeric(t *testing.T) {
+	RequireAlphanumericSKU = true
+	defer func() { RequireAlphanumericSKU = false }()
+
+	tests := map[string]ValidateResult{
+		"invalid sku only hyphens": {
+			item:    Item{SKU: "--------"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid sku only underscores": {
+			item:    Item{SKU: "________"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid sku only separators": {
+			item:    Item{SKU: "-_-_"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"valid sku mixed": {
+			item:    Item{SKU: "--A_"},
+			code:    0,
+			isError: false,
+		},
+		"valid sku only letters": {
+			item:    Item{SKU: "ABCDefgh"},
+			code:    0,
+			isError: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateSKU()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestValidateSKUNormalizes(t *testing.T) {
+	tests := map[string]struct {
+		sku       SKU
+		uppercase bool
+		want      SKU
+	}{
+		"trimmed": {
+			sku:  "  ABCDefgh\t",
+			want: "ABCDefgh",
+		},
+		"unchanged": {
+			sku:  "ABCDefgh",
+			want: "ABCDefgh",
+		},
+		"uppercased": {
+			sku:       "ABCDefgh",
+			uppercase: true,
+			want:      "ABCDEFGH",
+		},
+		"trimmed and uppercased": {
+			sku:       " ab_0-12 ",
+			uppercase: true,
+			want:      "AB_0-12",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			UppercaseSKU = test.uppercase
+			defer func() { UppercaseSKU = false }()
+
+			item := Item{SKU: test.sku}
+			if _, err := item.ValidateSKU(); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := item.SKU, test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := map[string]ValidateResult{
+		"invalid no name": {
+			item:    Item{Name: ""},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid whitespace name": {
+			item: Item{Name: "    	"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"valid name": {
+			item:    Item{Name: "Thingamajig"},
+			code:    0,
+			isError: false,
+		},
+		"valid name with spaces": {
+			item: Item{Name: "  Thingamabob	"},
+			code:    0,
+			isError: false,
+		},
+		"valid name with internal spaces": {
+			item:    Item{Name: "Amazing Crazy Doohickey!"},
+			code:    0,
+			isError: false,
+		},
+		"valid name at max length": {
+			item:    Item{Name: strings.Repeat("a", NAME_MAX_LEN)},
+			code:    0,
+			isError: false,
+		},
+		"valid multibyte name at max length": {
+			item:    Item{Name: strings.Repeat("ü", NAME_MAX_LEN)},
+			code:    0,
+			isError: false,
+		},
+		"invalid name past max length": {
+			item:    Item{Name: strings.Repeat("a", NAME_MAX_LEN+1)},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateName()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestValidateDescription(t *testing.T) {
+	tests := map[string]struct {
+		description string
+		want        string
+		code        int
+		isError     bool
+	}{
+		"valid no description": {
+			description: "",
+			want:        "",
+			code:        0,
+			isError:     false,
+		},
+		"valid description trimmed": {
+			description: "  a thing	",
+			want:        "a thing",
+			code:        0,
+			isError:     false,
+		},
+		"valid max length": {
+			description: strings.Repeat("a", DESCRIPTION_MAX_LEN),
+			want:        strings.Repeat("a", DESCRIPTION_MAX_LEN),
+			code:        0,
+			isError:     false,
+		},
+		"valid max length after trimming": {
+			description: " " + strings.Repeat("a", DESCRIPTION_MAX_LEN) + " ",
+			want:        strings.Repeat("a", DESCRIPTION_MAX_LEN),
+			code:        0,
+			isError:     false,
+		},
+		"valid max length multibyte": {
+			description: strings.Repeat("é", DESCRIPTION_MAX_LEN),
+			want:        strings.Repeat("é", DESCRIPTION_MAX_LEN),
+			code:        0,
+			isError:     false,
+		},
+		"invalid too long": {
+			description: strings.Repeat("a", DESCRIPTION_MAX_LEN+1),
+			code:        http.StatusBadRequest,
+			isError:     true,
+		},
+		"invalid too long multibyte": {
+			description: strings.Repeat("日", DESCRIPTION_MAX_LEN+1),
+			code:        http.StatusBadRequest,
+			isError:     true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			item := Item{Description: test.description}
+			code, err := item.ValidateDescription()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if !test.isError && item.Description != test.want {
+				t.Errorf("got %v; want %v", len(item.Description), len(test.want))
+			}
+		})
+	}
+}
+
+func TestValidatePrice(t *testing.T) {
+	testPricePositive := 15.0
+	testPriceZero := 0.0
+	testPriceNegative := -0.1
+	testPriceFractionalCents := 19.999
+
+	tests := map[string]ValidateResult{
+		"valid no price": {
+			item:    Item{PriceInCAD: nil},
+			code:    0,
+			isError: false,
+		},
+		"valid price positive": {
+			item:    Item{PriceInCAD: &testPricePositive},
+			code:    0,
+			isError: false,
+		},
+		"valid price zero": {
+			item:    Item{PriceInCAD: &testPriceZero},
+			code:    0,
+			isError: false,
+		},
+		"invalid price negative": {
+			item:    Item{PriceInCAD: &testPriceNegative},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"valid price currency": {
+			item:    Item{Price: &Price{Amount: 15.0, Currency: "USD"}},
+			code:    0,
+			isError: false,
+		},
+		"valid price lowercase currency": {
+			item:    Item{Price: &Price{Amount: 15.0, Currency: "eur"}},
+			code:    0,
+			isError: false,
+		},
+		"invalid price currency negative": {
+			item:    Item{Price: &Price{Amount: -0.1, Currency: "USD"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid price unknown currency": {
+			item:    Item{Price: &Price{Amount: 15.0, Currency: "ABC"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"valid price missing currency": {
+			item:    Item{Price: &Price{Amount: 15.0}},
+			code:    0,
+			isError: false,
+		},
+		"invalid price and price_CAD": {
+			item:    Item{Price: &Price{Amount: 15.0, Currency: "USD"}, PriceInCAD: &testPricePositive},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"valid price cents": {
+			item:    Item{Price: &Price{Amount: 19.99, Currency: "CAD"}},
+			code:    0,
+			isError: false,
+		},
+		"invalid price fractional cents": {
+			item:    Item{Price: &Price{Amount: 19.999, Currency: "CAD"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid price_CAD fractional cents": {
+			item:    Item{PriceInCAD: &testPriceFractionalCents},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"valid price whole yen": {
+			item:    Item{Price: &Price{Amount: 100, Currency: "JPY"}},
+			code:    0,
+			isError: false,
+		},
+		"invalid price fractional yen": {
+			item:    Item{Price: &Price{Amount: 100.50, Currency: "JPY"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"valid price fils": {
+			item:    Item{Price: &Price{Amount: 1.125, Currency: "KWD"}},
+			code:    0,
+			isError: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidatePrice()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestValidatePriceMapsPriceInCAD(t *testing.T) {
+	testPrice := 15.0
+	item := Item{PriceInCAD: &testPrice}
+
+	if _, err := item.ValidatePrice(); err != nil {
+		t.Fatal(err)
+	}
+	if item.PriceInCAD != nil {
+		t.Error("expected price_CAD to be cleared")
+	}
+	if item.Price == nil || *item.Price != (Price{Amount: 15.0, Currency: "CAD"}) {
+		t.Errorf("got %v; want %v", item.Price, Price{Amount: 15.0, Currency: "CAD"})
+	}
+}
+
+func TestValidateCost(t *testing.T) {
+	testCostPositive := 15.0
+	testCostZero := 0.0
+	testCostNegative := -0.1
+
+	tests := map[string]ValidateResult{
+		"valid no cost": {
+			item:    Item{CostInCAD: nil},
+			code:    0,
+			isError: false,
+		},
+		"valid cost positive": {
+			item:    Item{CostInCAD: &testCostPositive},
+			code:    0,
+			isError: false,
+		},
+		"valid cost zero": {
+			item:    Item{CostInCAD: &testCostZero},
+			code:    0,
+			isError: false,
+		},
+		"invalid cost negative": {
+			item:    Item{CostInCAD: &testCostNegative},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateCost()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestValidateReorder(t *testing.T) {
+	testPositive := 10
+	testZero := 0
+	testNegative := -1
+
+	tests := map[string]ValidateResult{
+		"valid no reorder": {
+			item:    Item{},
+			code:    0,
+			isError: false,
+		},
+		"valid reorder positive": {
+			item:    Item{ReorderPoint: &testPositive, ReorderQuantity: &testPositive},
+			code:    0,
+			isError: false,
+		},
+		"valid reorder zero": {
+			item:    Item{ReorderPoint: &testZero, ReorderQuantity: &testZero},
+			code:    0,
+			isError: false,
+		},
+		"valid reorder point only": {
+			item:    Item{ReorderPoint: &testPositive},
+			code:    0,
+			isError: false,
+		},
+		"invalid reorder point negative": {
+			item:    Item{ReorderPoint: &testNegative},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid reorder quantity negative": {
+			item:    Item{ReorderPoint: &testPositive, ReorderQuantity: &testNegative},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateReorder()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	tests := map[string]ValidateResult{
+		"valid no version": {
 			item:    Item{},
-			code:    http.StatusBadRequest,
-			isError: true,
+			code:    0,
+			isError: false,
 		},
-		"invalid short sku": {
-			item:    Item{SKU: "ABC"},
+		"valid version": {
+			item:    Item{Version: 3},
+			code:    0,
+			isError: false,
+		},
+		"invalid version negative": {
+			item:    Item{Version: -1},
 			code:    http.StatusBadRequest,
 			isError: true,
 		},
-		"valid sku minimal": {
-			item:    Item{SKU: "A_-0"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateVersion()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	tests := map[string]struct {
+		current  int
+		expected int
+		code     int
+		isError  bool
+	}{
+		"no version expected": {
+			current:  3,
+			expected: 0,
+			code:     0,
+			isError:  false,
+		},
+		"current version expected": {
+			current:  3,
+			expected: 3,
+			code:     0,
+			isError:  false,
+		},
+		"stale version expected": {
+			current:  3,
+			expected: 2,
+			code:     http.StatusConflict,
+			isError:  true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := CheckVersion(ID("01234567890123456789"), test.current, test.expected)
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+		})
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	tests := map[string]struct {
+		item    Item
+		want    []string
+		code    int
+		isError bool
+	}{
+		"valid no tags": {
+			item:    Item{},
+			want:    nil,
 			code:    0,
 			isError: false,
 		},
-		"valid sku only letters": {
-			item:    Item{SKU: "ABCDefgh"},
+		"valid tags": {
+			item:    Item{Tags: []string{"electronics", "a"}},
+			want:    []string{"electronics", "a"},
 			code:    0,
 			isError: false,
 		},
-		"valid sku only numbers": {
-			item:    Item{SKU: "01234567"},
+		"valid tags trimmed and deduplicated": {
+			item:    Item{Tags: []string{" electronics ", "electronics", "audio"}},
+			want:    []string{"electronics", "audio"},
 			code:    0,
 			isError: false,
 		},
-		"valid sku only hyphens": {
-			item:    Item{SKU: "--------"},
+		"valid tag maximal": {
+			item:    Item{Tags: []string{"abcdefghijklmnopqrstuvwxyz012345"}},
+			want:    []string{"abcdefghijklmnopqrstuvwxyz012345"},
 			code:    0,
 			isError: false,
 		},
-		"valid sku only underscores": {
-			item:    Item{SKU: "________"},
+		"invalid empty tag": {
+			item:    Item{Tags: []string{"electronics", ""}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid whitespace tag": {
+			item:    Item{Tags: []string{"   "}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid long tag": {
+			item:    Item{Tags: []string{"abcdefghijklmnopqrstuvwxyz0123456"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateTags()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if !test.isError && !reflect.DeepEqual(test.item.Tags, test.want) {
+				t.Errorf("got %v; want %v", test.item.Tags, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateBarcode(t *testing.T) {
+	tests := map[string]struct {
+		item    Item
+		want    string
+		code    int
+		isError bool
+	}{
+		"valid no barcode": {
+			item:    Item{Barcode: ""},
+			want:    "",
 			code:    0,
 			isError: false,
 		},
-		"valid id maximal": {
-			item:    Item{SKU: "Ab_0-12_Cd-3"},
+		"valid barcode": {
+			item:    Item{Barcode: "4006381333931"},
+			want:    "4006381333931",
 			code:    0,
 			isError: false,
 		},
-		"invalid character": {
-			item:    Item{SKU: "AAAAAAA?"},
+		"valid barcode check digit zero": {
+			item:    Item{Barcode: "0000000000000"},
+			want:    "0000000000000",
+			code:    0,
+			isError: false,
+		},
+		"valid barcode trimmed": {
+			item:    Item{Barcode: " 9780201379624 "},
+			want:    "9780201379624",
+			code:    0,
+			isError: false,
+		},
+		"valid whitespace barcode": {
+			item:    Item{Barcode: "   "},
+			want:    "",
+			code:    0,
+			isError: false,
+		},
+		"invalid check digit": {
+			item:    Item{Barcode: "4006381333932"},
 			code:    http.StatusBadRequest,
 			isError: true,
 		},
-		"invalid long id": {
-			item:    Item{SKU: "Ab_0-12345678"},
+		"invalid too short": {
+			item:    Item{Barcode: "400638133393"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid too long": {
+			item:    Item{Barcode: "40063813339310"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid non-digit": {
+			item:    Item{Barcode: "40063813339a1"},
 			code:    http.StatusBadRequest,
 			isError: true,
 		},
@@ -201,82 +939,233 @@ func TestValidateSKU(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			code, err := test.item.ValidateSKU()
+			code, err := test.item.ValidateBarcode()
 			if isError := err != nil; isError != test.isError {
 				t.Errorf("got %v; want %v", err, test.isError)
 			}
 			if code != test.code {
 				t.Errorf("got %v; want %v", code, test.code)
 			}
+			if !test.isError && test.item.Barcode != test.want {
+				t.Errorf("got %v; want %v", test.item.Barcode, test.want)
+			}
 		})
 	}
 }
 
-func TestValidateName(t *testing.T) {
-	tests := map[string]ValidateResult{
-		"invalid no name": {
-			item:    Item{Name: ""},
+func TestValidateImageURL(t *testing.T) {
+	tests := map[string]struct {
+		item    Item
+		want    string
+		code    int
+		isError bool
+	}{
+		"valid no image url": {
+			item:    Item{ImageURL: ""},
+			want:    "",
+			code:    0,
+			isError: false,
+		},
+		"valid https": {
+			item:    Item{ImageURL: "https://cdn.example.com/thing.png"},
+			want:    "https://cdn.example.com/thing.png",
+			code:    0,
+			isError: false,
+		},
+		"valid http with query": {
+			item:    Item{ImageURL: "http://example.com:8080/images/thing.png?size=large"},
+			want:    "http://example.com:8080/images/thing.png?size=large",
+			code:    0,
+			isError: false,
+		},
+		"valid uppercase scheme": {
+			item:    Item{ImageURL: "HTTPS://cdn.example.com/thing.png"},
+			want:    "HTTPS://cdn.example.com/thing.png",
+			code:    0,
+			isError: false,
+		},
+		"valid image url trimmed": {
+			item:    Item{ImageURL: " https://cdn.example.com/thing.png "},
+			want:    "https://cdn.example.com/thing.png",
+			code:    0,
+			isError: false,
+		},
+		"invalid relative url": {
+			item:    Item{ImageURL: "/images/thing.png"},
 			code:    http.StatusBadRequest,
 			isError: true,
 		},
-		"invalid whitespace name": {
-			item: Item{Name: "    	"},
+		"invalid no scheme": {
+			item:    Item{ImageURL: "cdn.example.com/thing.png"},
 			code:    http.StatusBadRequest,
 			isError: true,
 		},
-		"valid name": {
-			item:    Item{Name: "Thingamajig"},
+		"invalid no host": {
+			item:    Item{ImageURL: "https:///thing.png"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid ftp scheme": {
+			item:    Item{ImageURL: "ftp://example.com/thing.png"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid javascript scheme": {
+			item:    Item{ImageURL: "javascript:alert(1)"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid malformed": {
+			item:    Item{ImageURL: "https://exa mple.com/%zz"},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := test.item.ValidateImageURL()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if !test.isError && test.item.ImageURL != test.want {
+				t.Errorf("got %v; want %v", test.item.ImageURL, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateImages(t *testing.T) {
+	tooMany := make([]string, MAX_IMAGES+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("https://cdn.example.com/%d.png", i)
+	}
+
+	tests := map[string]struct {
+		item    Item
+		want    []string
+		code    int
+		isError bool
+	}{
+		"valid no images": {
+			item:    Item{Images: nil},
+			want:    nil,
 			code:    0,
 			isError: false,
 		},
-		"valid name with spaces": {
-			item: Item{Name: "  Thingamabob	"},
+		"valid empty images": {
+			item:    Item{Images: []string{}},
+			want:    []string{},
 			code:    0,
 			isError: false,
 		},
-		"valid name with internal spaces": {
-			item:    Item{Name: "Amazing Crazy Doohickey!"},
+		"valid images keep order": {
+			item:    Item{Images: []string{"https://cdn.example.com/b.png", "http://example.com/a.png"}},
+			want:    []string{"https://cdn.example.com/b.png", "http://example.com/a.png"},
+			code:    0,
+			isError: false,
+		},
+		"valid images trimmed": {
+			item:    Item{Images: []string{" https://cdn.example.com/a.png "}},
+			want:    []string{"https://cdn.example.com/a.png"},
 			code:    0,
 			isError: false,
 		},
+		"valid max images": {
+			item:    Item{Images: tooMany[:MAX_IMAGES]},
+			want:    tooMany[:MAX_IMAGES],
+			code:    0,
+			isError: false,
+		},
+		"invalid too many images": {
+			item:    Item{Images: tooMany},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid relative image": {
+			item:    Item{Images: []string{"https://cdn.example.com/a.png", "/b.png"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid empty image": {
+			item:    Item{Images: []string{"  "}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid image scheme": {
+			item:    Item{Images: []string{"ftp://example.com/a.png"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			code, err := test.item.ValidateName()
+			code, err := test.item.ValidateImages()
 			if isError := err != nil; isError != test.isError {
 				t.Errorf("got %v; want %v", err, test.isError)
 			}
 			if code != test.code {
 				t.Errorf("got %v; want %v", code, test.code)
 			}
+			if !test.isError && !reflect.DeepEqual(test.item.Images, test.want) {
+				t.Errorf("got %v; want %v", test.item.Images, test.want)
+			}
 		})
 	}
 }
 
-func TestValidatePrice(t *testing.T) {
-	testPricePositive := 15.0
-	testPriceZero := 0.0
-	testPriceNegative := -0.1
-
-	tests := map[string]ValidateResult{
-		"valid no price": {
-			item:    Item{PriceInCAD: nil},
+func TestValidateSupplier(t *testing.T) {
+	tests := map[string]struct {
+		item    Item
+		want    *Supplier
+		code    int
+		isError bool
+	}{
+		"valid no supplier": {
+			item:    Item{},
+			want:    nil,
 			code:    0,
 			isError: false,
 		},
-		"valid price positive": {
-			item:    Item{PriceInCAD: &testPricePositive},
+		"valid supplier name": {
+			item:    Item{Supplier: &Supplier{Name: "Acme"}},
+			want:    &Supplier{Name: "Acme"},
 			code:    0,
 			isError: false,
 		},
-		"valid price zero": {
-			item:    Item{PriceInCAD: &testPriceZero},
+		"valid supplier trimmed": {
+			item:    Item{Supplier: &Supplier{Name: " Acme ", SKU: " AC-1001 "}},
+			want:    &Supplier{Name: "Acme", SKU: "AC-1001"},
 			code:    0,
 			isError: false,
 		},
-		"invalid price negative": {
-			item:    Item{PriceInCAD: &testPriceNegative},
+		"valid supplier at max lengths": {
+			item:    Item{Supplier: &Supplier{Name: strings.Repeat("a", SUPPLIER_NAME_MAX_LEN), SKU: strings.Repeat("b", SUPPLIER_SKU_MAX_LEN)}},
+			want:    &Supplier{Name: strings.Repeat("a", SUPPLIER_NAME_MAX_LEN), SKU: strings.Repeat("b", SUPPLIER_SKU_MAX_LEN)},
+			code:    0,
+			isError: false,
+		},
+		"invalid no supplier name": {
+			item:    Item{Supplier: &Supplier{SKU: "AC-1001"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid whitespace supplier name": {
+			item:    Item{Supplier: &Supplier{Name: "   "}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid supplier name too long": {
+			item:    Item{Supplier: &Supplier{Name: strings.Repeat("a", SUPPLIER_NAME_MAX_LEN+1)}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid supplier SKU too long": {
+			item:    Item{Supplier: &Supplier{Name: "Acme", SKU: strings.Repeat("b", SUPPLIER_SKU_MAX_LEN+1)}},
 			code:    http.StatusBadRequest,
 			isError: true,
 		},
@@ -284,13 +1173,16 @@ func TestValidatePrice(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			code, err := test.item.ValidatePrice()
+			code, err := test.item.ValidateSupplier()
 			if isError := err != nil; isError != test.isError {
 				t.Errorf("got %v; want %v", err, test.isError)
 			}
 			if code != test.code {
 				t.Errorf("got %v; want %v", code, test.code)
 			}
+			if !test.isError && !reflect.DeepEqual(test.item.Supplier, test.want) {
+				t.Errorf("got %v; want %v", test.item.Supplier, test.want)
+			}
 		})
 	}
 }
@@ -336,6 +1228,39 @@ func TestValidateQuantity(t *testing.T) {
 	}
 }
 
+func TestValidateQuantityStrict(t *testing.T) {
+	testQuantityZero := 0
+
+	tests := map[string]struct {
+		strict  bool
+		item    Item
+		code    int
+		isError bool
+	}{
+		"lenient no quantity":   {strict: false, item: Item{Quantity: nil}, code: 0, isError: false},
+		"lenient quantity zero": {strict: false, item: Item{Quantity: &testQuantityZero}, code: 0, isError: false},
+		"strict no quantity":    {strict: true, item: Item{Quantity: nil}, code: http.StatusBadRequest, isError: true},
+		"strict quantity zero":  {strict: true, item: Item{Quantity: &testQuantityZero}, code: 0, isError: false},
+	}
+
+	defer func() { StrictQuantity = false }()
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			StrictQuantity = test.strict
+			code, err := test.item.ValidateQuantity()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if !test.isError && (test.item.Quantity == nil || *test.item.Quantity != 0) {
+				t.Errorf("got %v; want %v", test.item.Quantity, 0)
+			}
+		})
+	}
+}
+
 func TestValidateItem(t *testing.T) {
 	time := time.Date(2021, time.January, 10, 18, 38, 38, 500, time.UTC)
 	testPriceZero := 0.00
@@ -398,6 +1323,15 @@ func TestValidateItem(t *testing.T) {
 			code:    http.StatusBadRequest,
 			isError: true,
 		},
+		"invalid cost": {
+			item: Item{
+				SKU:       "00000001",
+				Name:      "Thing1",
+				CostInCAD: &testPriceNegative,
+			},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
 		"invalid quantity": {
 			item: Item{
 				SKU:      "00000001",
@@ -407,6 +1341,15 @@ func TestValidateItem(t *testing.T) {
 			code:    http.StatusBadRequest,
 			isError: true,
 		},
+		"invalid barcode": {
+			item: Item{
+				SKU:     "00000001",
+				Name:    "Thing1",
+				Barcode: "4006381333932",
+			},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
 	}
 
 	for name, test := range tests {
@@ -421,3 +1364,53 @@ func TestValidateItem(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeAvailable(t *testing.T) {
+	testQuantity := 5
+
+	tests := map[string]struct {
+		item Item
+		want int
+	}{
+		"nothing reserved": {
+			item: Item{Quantity: &testQuantity},
+			want: 5,
+		},
+		"some reserved": {
+			item: Item{Quantity: &testQuantity, Reserved: 3},
+			want: 2,
+		},
+		"all reserved": {
+			item: Item{Quantity: &testQuantity, Reserved: 5},
+			want: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.item.ComputeAvailable()
+			if got := test.item.Available; got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMinorUnits(t *testing.T) {
+	tests := map[string]struct {
+		currency string
+		want     int
+	}{
+		"cents":    {currency: "CAD", want: 2},
+		"no minor": {currency: "JPY", want: 0},
+		"fils":     {currency: "KWD", want: 3},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := MinorUnits(test.currency); got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// NormalizeSKU returns the canonical form of a SKU: without surrounding whitespace and in uppercase.
+func NormalizeSKU(sku SKU) SKU {
+	return SKU(strings.ToUpper(strings.TrimSpace(string(sku))))
+}
+
+// A SKUCollision holds the Items whose distinct SKUs would become the same SKU once normalized.
+type SKUCollision struct {
+	SKU  SKU   `json:"sku"`
+	IDs  []ID  `json:"ids"`
+	SKUs []SKU `json:"skus"`
+}
+
+// A SKUNormalizationReport holds the impact of normalizing every SKU in a collection of inventory Items.
+type SKUNormalizationReport struct {
+	Total      int            `json:"total"`
+	Changed    int            `json:"changed"`
+	Collisions []SKUCollision `json:"collisions"`
+}
+
+// NewSKUNormalizationReport normalizes the SKU of each of the given Items, without modifying them,
+// and reports how many SKUs would change and which Items would no longer have a unique SKU.
+// Collisions are ordered by normalized SKU and the Items within each collision by original SKU.
+func NewSKUNormalizationReport(items []Item) SKUNormalizationReport {
+	report := SKUNormalizationReport{Total: len(items), Collisions: []SKUCollision{}}
+
+	groups := make(map[SKU][]*Item)
+	for i := range items {
+		sku := NormalizeSKU(items[i].SKU)
+		if sku != items[i].SKU {
+			report.Changed++
+		}
+		groups[sku] = append(groups[sku], &items[i])
+	}
+
+	for sku, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].SKU < group[j].SKU
+		})
+		collision := SKUCollision{SKU: sku}
+		for _, item := range group {
+			collision.IDs = append(collision.IDs, item.ID)
+			collision.SKUs = append(collision.SKUs, item.SKU)
+		}
+		report.Collisions = append(report.Collisions, collision)
+	}
+	sort.Slice(report.Collisions, func(i, j int) bool {
+		return report.Collisions[i].SKU < report.Collisions[j].SKU
+	})
+	return report
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeSKU(t *testing.T) {
+	tests := map[string]struct {
+		sku  SKU
+		want SKU
+	}{
+		"already normalized": {sku: "ABC-123", want: "ABC-123"},
+		"lowercase":          {sku: "abc-123", want: "ABC-123"},
+		"mixed case":         {sku: "aBc_123", want: "ABC_123"},
+		"surrounding spaces": {sku: "  ABC-123\t", want: "ABC-123"},
+		"whitespace only":    {sku: "   ", want: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, want := NormalizeSKU(test.sku), test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSKUNormalizationReport(t *testing.T) {
+	tests := map[string]struct {
+		items []Item
+		want  SKUNormalizationReport
+	}{
+		"empty catalog": {
+			items: []Item{},
+			want:  SKUNormalizationReport{Collisions: []SKUCollision{}},
+		},
+		"no changes": {
+			items: []Item{
+				{ID: "1", SKU: "AAAA"},
+				{ID: "2", SKU: "BBBB"},
+			},
+			want: SKUNormalizationReport{Total: 2, Collisions: []SKUCollision{}},
+		},
+		"changes without collisions": {
+			items: []Item{
+				{ID: "1", SKU: "aaaa"},
+				{ID: "2", SKU: " BBBB "},
+				{ID: "3", SKU: "CCCC"},
+			},
+			want: SKUNormalizationReport{Total: 3, Changed: 2, Collisions: []SKUCollision{}},
+		},
+		"case variants": {
+			items: []Item{
+				{ID: "1", SKU: "abcd"},
+				{ID: "2", SKU: "ABCD"},
+				{ID: "3", SKU: "AbCd"},
+				{ID: "4", SKU: "wxyz"},
+				{ID: "5", SKU: "WXYZ"},
+				{ID: "6", SKU: "EFGH"},
+			},
+			want: SKUNormalizationReport{
+				Total:   6,
+				Changed: 3,
+				Collisions: []SKUCollision{
+					{SKU: "ABCD", IDs: []ID{"2", "3", "1"}, SKUs: []SKU{"ABCD", "AbCd", "abcd"}},
+					{SKU: "WXYZ", IDs: []ID{"5", "4"}, SKUs: []SKU{"WXYZ", "wxyz"}},
+				},
+			},
+		},
+		"whitespace variants": {
+			items: []Item{
+				{ID: "1", SKU: "ABCD "},
+				{ID: "2", SKU: "ABCD"},
+			},
+			want: SKUNormalizationReport{
+				Total:   2,
+				Changed: 1,
+				Collisions: []SKUCollision{
+					{SKU: "ABCD", IDs: []ID{"2", "1"}, SKUs: []SKU{"ABCD", "ABCD "}},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, want := NewSKUNormalizationReport(test.items), test.want; !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSKUNormalizationReportReadOnly(t *testing.T) {
+	items := []Item{
+		{ID: "1", SKU: "abcd"},
+		{ID: "2", SKU: "ABCD"},
+	}
+	NewSKUNormalizationReport(items)
+
+	if got, want := items[0].SKU, SKU("abcd"); got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := items[1].SKU, SKU("ABCD"); got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+const (
+	DEFAULT_PAGE_LIMIT = 50
+	MAX_PAGE_LIMIT     = 500
+)
+
+// A Page describes one page of a paginated collection of Items.
+// Next and Prev are links to the neighbouring pages, and are empty at the boundaries of the collection.
+type Page struct {
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Total  int    `json:"total"`
+	Next   string `json:"next,omitempty"`
+	Prev   string `json:"prev,omitempty"`
+}
+
+// NewPage describes the page of a collection of total Items selected by limit and offset.
+// Links to the neighbouring pages are relative to path and keep every other query parameter.
+func NewPage(limit, offset, total int, path string, query url.Values) Page {
+	page := Page{Limit: limit, Offset: offset, Total: total}
+	link := func(offset int) string {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("offset", strconv.Itoa(offset))
+		return path + "?" + q.Encode()
+	}
+	if offset+limit < total {
+		page.Next = link(offset + limit)
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		page.Prev = link(prev)
+	}
+	return page
+}
+
+// SortForPaging orders Items as they are paged: by date added, then by ID.
+// Items without a date added come first.
+func SortForPaging(items []*Item) {
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i].DateAdded, items[j].DateAdded
+		if a != nil && b != nil && !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		if (a == nil) != (b == nil) {
+			return a == nil
+		}
+		return items[i].ID < items[j].ID
+	})
+}
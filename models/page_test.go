@@ -0,0 +1,82 @@
+package models
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewPage(t *testing.T) {
+	tests := map[string]struct {
+		limit  int
+		offset int
+		total  int
+		query  url.Values
+		want   Page
+	}{
+		"empty": {
+			limit: 50, offset: 0, total: 0,
+			want: Page{Limit: 50, Offset: 0, Total: 0},
+		},
+		"single page": {
+			limit: 50, offset: 0, total: 50,
+			want: Page{Limit: 50, Offset: 0, Total: 50},
+		},
+		"first page": {
+			limit: 50, offset: 0, total: 120,
+			want: Page{Limit: 50, Offset: 0, Total: 120, Next: "/api/items?limit=50&offset=50"},
+		},
+		"middle page": {
+			limit: 50, offset: 50, total: 120,
+			want: Page{Limit: 50, Offset: 50, Total: 120, Next: "/api/items?limit=50&offset=100", Prev: "/api/items?limit=50&offset=0"},
+		},
+		"last page": {
+			limit: 50, offset: 100, total: 120,
+			want: Page{Limit: 50, Offset: 100, Total: 120, Prev: "/api/items?limit=50&offset=50"},
+		},
+		"unaligned offset": {
+			limit: 50, offset: 20, total: 120,
+			want: Page{Limit: 50, Offset: 20, Total: 120, Next: "/api/items?limit=50&offset=70", Prev: "/api/items?limit=50&offset=0"},
+		},
+		"past the end": {
+			limit: 50, offset: 200, total: 120,
+			want: Page{Limit: 50, Offset: 200, Total: 120, Prev: "/api/items?limit=50&offset=150"},
+		},
+		"keeps other parameters": {
+			limit: 10, offset: 0, total: 20,
+			query: url.Values{"tag": {"audio"}, "envelope": {"true"}, "offset": {"0"}},
+			want:  Page{Limit: 10, Offset: 0, Total: 20, Next: "/api/items?envelope=true&limit=10&offset=10&tag=audio"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := NewPage(test.limit, test.offset, test.total, "/api/items", test.query)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSortForPaging(t *testing.T) {
+	t1 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	items := []*Item{
+		{ID: "4", DateAdded: &t2},
+		{ID: "3", DateAdded: &t1},
+		{ID: "2"},
+		{ID: "1", DateAdded: &t1},
+	}
+	SortForPaging(items)
+
+	var got []ID
+	for _, item := range items {
+		got = append(got, item.ID)
+	}
+	if want := []ID{"2", "1", "3", "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// MERGE_PATCH_MEDIA_TYPE is the media type of a JSON Merge Patch (RFC 7386).
+const MERGE_PATCH_MEDIA_TYPE = "application/merge-patch+json"
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to the json representation of the Item.
+// Keys present in the patch replace those of the Item, keys set to null are removed, and absent keys are untouched.
+// The legacy price_CAD field stands in for price, so setting it to null clears the price.
+// The patched document is not validated; it must be decoded into an Item and validated before use.
+// Returns the patched json document, 0, and nil if successful.
+// Returns a 400 Bad Request if the patch is not a json object or sets quantity to null.
+func (item *Item) MergePatch(patch []byte) ([]byte, int, error) {
+	var changes map[string]interface{}
+	if err := json.Unmarshal(patch, &changes); err != nil || changes == nil {
+		return nil, http.StatusBadRequest, errors.New("merge patch must be a json object")
+	}
+	if v, ok := changes["quantity"]; ok && v == nil {
+		return nil, http.StatusBadRequest, NewFieldError("quantity", "quantity cannot be null")
+	}
+	if v, ok := changes["price_CAD"]; ok {
+		if _, ok := changes["price"]; !ok {
+			changes["price"] = nil
+		}
+		if v == nil {
+			delete(changes, "price_CAD")
+		}
+	}
+
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	merged, err := json.Marshal(mergePatch(doc, changes))
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return merged, 0, nil
+}
+
+// mergePatch applies a JSON Merge Patch to a decoded json document, as specified by RFC 7386.
+// Returns the patched document.
+func mergePatch(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	doc, ok := target.(map[string]interface{})
+	if !ok {
+		doc = make(map[string]interface{})
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(doc, key)
+		} else {
+			doc[key] = mergePatch(doc[key], value)
+		}
+	}
+	return doc
+}
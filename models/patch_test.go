@@ -0,0 +1,115 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type MergePatchResult struct {
+	patch string
+	want  map[string]interface{}
+	code  int
+}
+
+func TestMergePatch(t *testing.T) {
+	quantity := 5
+	item := Item{
+		ID:          "abcdefghijklmnopqrst",
+		SKU:         "AAAAAAAA",
+		Name:        "Thing",
+		Description: "a thing",
+		Price:       &Price{Amount: 15, Currency: "CAD"},
+		Quantity:    &quantity,
+		Tags:        []string{"audio"},
+	}
+	base := func(changes map[string]interface{}) map[string]interface{} {
+		doc := map[string]interface{}{
+			"id":          "abcdefghijklmnopqrst",
+			"sku":         "AAAAAAAA",
+			"name":        "Thing",
+			"description": "a thing",
+			"price":       map[string]interface{}{"amount": 15.0, "currency": "CAD"},
+			"quantity":    5.0,
+			"reserved":    0.0,
+			"available":   0.0,
+			"tags":        []interface{}{"audio"},
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(doc, k)
+			} else {
+				doc[k] = v
+			}
+		}
+		return doc
+	}
+
+	tests := map[string]MergePatchResult{
+		"empty patch": {
+			patch: `{}`,
+			want:  base(nil),
+			code:  0,
+		},
+		"update field": {
+			patch: `{"name": "Other thing"}`,
+			want:  base(map[string]interface{}{"name": "Other thing"}),
+			code:  0,
+		},
+		"clear field": {
+			patch: `{"description": null, "tags": null}`,
+			want:  base(map[string]interface{}{"description": nil, "tags": nil}),
+			code:  0,
+		},
+		"merge nested object": {
+			patch: `{"price": {"amount": 20}}`,
+			want:  base(map[string]interface{}{"price": map[string]interface{}{"amount": 20.0, "currency": "CAD"}}),
+			code:  0,
+		},
+		"clear legacy price": {
+			patch: `{"price_CAD": null}`,
+			want:  base(map[string]interface{}{"price": nil}),
+			code:  0,
+		},
+		"replace with legacy price": {
+			patch: `{"price_CAD": 20}`,
+			want:  base(map[string]interface{}{"price": nil, "price_CAD": 20.0}),
+			code:  0,
+		},
+		"null quantity": {
+			patch: `{"quantity": null}`,
+			want:  nil,
+			code:  http.StatusBadRequest,
+		},
+		"not an object": {
+			patch: `["name"]`,
+			want:  nil,
+			code:  http.StatusBadRequest,
+		},
+		"null patch": {
+			patch: `null`,
+			want:  nil,
+			code:  http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			merged, code, _ := item.MergePatch([]byte(test.patch))
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if test.want == nil {
+				return
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(merged, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// LEGACY_CURRENCY is the currency of prices given in the legacy price_CAD field.
+const LEGACY_CURRENCY = "CAD"
+
+// DefaultCurrency is the currency that the store prices its Items in. Stock values and price filters
+// are computed in it, so Items priced in another currency have no stock value and never match a price filter.
+// It is CAD by default; stores outside Canada set it with DEFAULT_CURRENCY.
+var DefaultCurrency = LEGACY_CURRENCY
+
+// A Price is an amount of money in a specific currency.
+// The Currency is an ISO-4217 currency code, e.g. "CAD" or "USD".
+type Price struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// currencies holds the active ISO-4217 currency codes.
+var currencies = map[string]bool{
+	"AED": true, "AFN": true, "ALL": true, "AMD": true, "ANG": true, "AOA": true, "ARS": true, "AUD": true,
+	"AWG": true, "AZN": true, "BAM": true, "BBD": true, "BDT": true, "BGN": true, "BHD": true, "BIF": true,
+	"BMD": true, "BND": true, "BOB": true, "BRL": true, "BSD": true, "BTN": true, "BWP": true, "BYN": true,
+	"BZD": true, "CAD": true, "CDF": true, "CHF": true, "CLP": true, "CNY": true, "COP": true, "CRC": true,
+	"CUP": true, "CVE": true, "CZK": true, "DJF": true, "DKK": true, "DOP": true, "DZD": true, "EGP": true,
+	"ERN": true, "ETB": true, "EUR": true, "FJD": true, "FKP": true, "GBP": true, "GEL": true, "GHS": true,
+	"GIP": true, "GMD": true, "GNF": true, "GTQ": true, "GYD": true, "HKD": true, "HNL": true, "HTG": true,
+	"HUF": true, "IDR": true, "ILS": true, "INR": true, "IQD": true, "IRR": true, "ISK": true, "JMD": true,
+	"JOD": true, "JPY": true, "KES": true, "KGS": true, "KHR": true, "KMF": true, "KPW": true, "KRW": true,
+	"KWD": true, "KYD": true, "KZT": true, "LAK": true, "LBP": true, "LKR": true, "LRD": true, "LSL": true,
+	"LYD": true, "MAD": true, "MDL": true, "MGA": true, "MKD": true, "MMK": true, "MNT": true, "MOP": true,
+	"MRU": true, "MUR": true, "MVR": true, "MWK": true, "MXN": true, "MYR": true, "MZN": true, "NAD": true,
+	"NGN": true, "NIO": true, "NOK": true, "NPR": true, "NZD": true, "OMR": true, "PAB": true, "PEN": true,
+	"PGK": true, "PHP": true, "PKR": true, "PLN": true, "PYG": true, "QAR": true, "RON": true, "RSD": true,
+	"RUB": true, "RWF": true, "SAR": true, "SBD": true, "SCR": true, "SDG": true, "SEK": true, "SGD": true,
+	"SHP": true, "SLE": true, "SOS": true, "SRD": true, "SSP": true, "STN": true, "SVC": true, "SYP": true,
+	"SZL": true, "THB": true, "TJS": true, "TMT": true, "TND": true, "TOP": true, "TRY": true, "TTD": true,
+	"TWD": true, "TZS": true, "UAH": true, "UGX": true, "USD": true, "UYU": true, "UZS": true, "VES": true,
+	"VND": true, "VUV": true, "WST": true, "XAF": true, "XCD": true, "XOF": true, "XPF": true, "YER": true,
+	"ZAR": true, "ZMW": true, "ZWL": true,
+}
+
+// DEFAULT_MINOR_UNITS is the number of decimal places in the minor unit of most currencies, e.g. cents.
+const DEFAULT_MINOR_UNITS = 2
+
+// minorUnits holds the number of decimal places in the minor unit of the currencies that have other than DEFAULT_MINOR_UNITS,
+// as published in ISO-4217, e.g. JPY has no minor unit and KWD has 3 decimal places.
+var minorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// MinorUnits returns the number of decimal places that amounts in the currency may have, e.g. 2 for CAD and 0 for JPY.
+func MinorUnits(code string) int {
+	if units, ok := minorUnits[code]; ok {
+		return units
+	}
+	return DEFAULT_MINOR_UNITS
+}
+
+// decimalPlaces returns the number of decimal places in the shortest decimal form of the amount, e.g. 3 for 19.999.
+func decimalPlaces(amount float64) int {
+	s := strconv.FormatFloat(amount, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// IsCurrency returns true if the code is a known ISO-4217 currency code, false otherwise.
+func IsCurrency(code string) bool {
+	return currencies[code]
+}
+
+// isValid checks that the Price is formatted according to the API specifications.
+// Prices are properly formatted if their Amount is non-negative, their Currency is a known ISO-4217 code,
+// and their Amount has no more decimal places than the Currency's MinorUnits, e.g. 19.99 CAD but not 19.999 CAD or 100.50 JPY.
+// The Currency is normalized to upper case, and a Price without one is in the DefaultCurrency.
+// Returns a 400 Bad Request if the Price is invalid.
+func (price *Price) isValid() (int, error) {
+	if price.Amount < 0 {
+		return http.StatusBadRequest, errors.New("price cannot be negative")
+	}
+	price.Currency = strings.ToUpper(strings.TrimSpace(price.Currency))
+	if price.Currency == "" {
+		price.Currency = DefaultCurrency
+	}
+	if !IsCurrency(price.Currency) {
+		return http.StatusBadRequest, fmt.Errorf("price currency %q is not a known ISO-4217 currency code", price.Currency)
+	}
+	if units := MinorUnits(price.Currency); decimalPlaces(price.Amount) > units {
+		return http.StatusBadRequest, fmt.Errorf("price in %s cannot have more than %d decimal places", price.Currency, units)
+	}
+	return 0, nil
+}
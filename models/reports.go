@@ -0,0 +1,153 @@
+package models
+
+import "math"
+
+// COST_CURRENCY is the currency that the costs of Items are recorded in, as cost_CAD, whatever the DefaultCurrency.
+// Margins are computed and reported in it.
+const COST_CURRENCY = LEGACY_CURRENCY
+
+// A MarginEntry holds the margin data for a single inventory Item.
+// Its Cost and Margin are in the COST_CURRENCY, as given by the MarginReport's Currency.
+type MarginEntry struct {
+	ID             ID       `json:"id"`
+	SKU            SKU      `json:"sku"`
+	Name           string   `json:"name"`
+	Price          *Price   `json:"price,omitempty"`
+	Cost           *float64 `json:"cost,omitempty"`
+	Margin         *float64 `json:"margin,omitempty"`
+	NegativeMargin bool     `json:"negative_margin"`
+}
+
+// A MarginReport holds the margin data for a collection of inventory Items.
+// Currency is the currency of every cost and margin in the report, the COST_CURRENCY.
+type MarginReport struct {
+	Items       []MarginEntry `json:"items"`
+	TotalMargin float64       `json:"total_margin"`
+	Currency    string        `json:"currency"`
+}
+
+// Margin returns the margin made on a single unit of an Item (Price - CostInCAD).
+// Costs are in the COST_CURRENCY, so a margin can only be computed for Items priced in it.
+// Returns the margin and true if both a Price in the COST_CURRENCY and CostInCAD are present, 0 and false otherwise.
+func (item *Item) Margin() (float64, bool) {
+	if item.Price == nil || item.Price.Currency != COST_CURRENCY || item.CostInCAD == nil {
+		return 0, false
+	}
+	return item.Price.Amount - *item.CostInCAD, true
+}
+
+// A UnitMargin holds the margin made on a single unit of an Item,
+// as an amount in the COST_CURRENCY and as a percentage of the Item's Price.
+type UnitMargin struct {
+	AmountInCAD float64  `json:"amount_CAD"`
+	Percent     *float64 `json:"percent,omitempty"`
+}
+
+// ComputeMargin sets UnitMargin to the margin made on a single unit of the Item, as given by Margin,
+// or to nil if the Item has no Price in the COST_CURRENCY or no CostInCAD.
+// The percentage is rounded to two decimal places, and is omitted for Items priced at 0.
+// It is managed by the server and is only meaningful on Items read from the database.
+func (item *Item) ComputeMargin() {
+	item.UnitMargin = nil
+	margin, ok := item.Margin()
+	if !ok {
+		return
+	}
+	item.UnitMargin = &UnitMargin{AmountInCAD: margin}
+	if item.Price.Amount != 0 {
+		percent := math.Round(margin/item.Price.Amount*100*100) / 100
+		item.UnitMargin.Percent = &percent
+	}
+}
+
+// NewMarginReport computes the margin on each of the given Items as well as their total margin, in the COST_CURRENCY.
+// Items missing a price in the COST_CURRENCY or a cost are included in the report without a margin
+// and do not contribute to the total.
+// Items whose cost exceeds their price are flagged with NegativeMargin.
+func NewMarginReport(items []Item) MarginReport {
+	report := MarginReport{Items: make([]MarginEntry, len(items)), Currency: COST_CURRENCY}
+	for i := range items {
+		entry := MarginEntry{
+			ID:    items[i].ID,
+			SKU:   items[i].SKU,
+			Name:  items[i].Name,
+			Price: items[i].Price,
+			Cost:  items[i].CostInCAD,
+		}
+		if margin, ok := items[i].Margin(); ok {
+			entry.Margin = &margin
+			entry.NegativeMargin = margin < 0
+			report.TotalMargin += margin
+		}
+		report.Items[i] = entry
+	}
+	return report
+}
+
+// A ReorderEntry holds the restocking data for a single inventory Item that needs to be reordered.
+// ReorderQuantity is the quantity suggested for the order.
+type ReorderEntry struct {
+	ID              ID        `json:"id"`
+	SKU             SKU       `json:"sku"`
+	Name            string    `json:"name"`
+	Quantity        int       `json:"quantity"`
+	ReorderPoint    int       `json:"reorder_point"`
+	ReorderQuantity int       `json:"reorder_quantity"`
+	Supplier        *Supplier `json:"supplier,omitempty"`
+}
+
+// NewReorderReport lists the given Items that need to be reordered, as given by NeedsReorder, in the same order.
+// The suggested quantity for each is its ReorderQuantity, or if it has none,
+// just enough to bring its Quantity back above its ReorderPoint.
+func NewReorderReport(items []Item) []ReorderEntry {
+	report := []ReorderEntry{}
+	for i := range items {
+		if !items[i].NeedsReorder() {
+			continue
+		}
+		entry := ReorderEntry{
+			ID:              items[i].ID,
+			SKU:             items[i].SKU,
+			Name:            items[i].Name,
+			Quantity:        *items[i].Quantity,
+			ReorderPoint:    *items[i].ReorderPoint,
+			ReorderQuantity: *items[i].ReorderPoint - *items[i].Quantity + 1,
+			Supplier:        items[i].Supplier,
+		}
+		if items[i].ReorderQuantity != nil {
+			entry.ReorderQuantity = *items[i].ReorderQuantity
+		}
+		report = append(report, entry)
+	}
+	return report
+}
+
+// ItemStats holds aggregate statistics over the Items in inventory.
+// TotalValue is the value of their stock (Price * Quantity) in the Currency, the DefaultCurrency,
+// rounded to the cent; Items priced in another currency, or unpriced, add nothing to it.
+// OutOfStock counts the Items with no stock available to sell, as given by InStock.
+type ItemStats struct {
+	Count      int     `json:"count"`
+	TotalUnits int     `json:"total_units"`
+	TotalValue float64 `json:"total_value"`
+	Currency   string  `json:"currency"`
+	OutOfStock int     `json:"out_of_stock"`
+}
+
+// NewItemStats computes the ItemStats of the given Items.
+func NewItemStats(items []Item) ItemStats {
+	stats := ItemStats{Count: len(items), Currency: DefaultCurrency}
+	for i := range items {
+		if items[i].Quantity != nil {
+			stats.TotalUnits += *items[i].Quantity
+		}
+		if value, ok := items[i].StockValue(); ok {
+			stats.TotalValue += value
+		}
+		if !items[i].InStock() {
+			stats.OutOfStock++
+		}
+	}
+	stats.TotalValue = math.Round(stats.TotalValue*100) / 100
+	return stats
+}
@@ -0,0 +1,242 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+type MarginResult struct {
+	item     Item
+	margin   float64
+	ok       bool
+	negative bool
+}
+
+func TestMargin(t *testing.T) {
+	testPrice := Price{Amount: 15.0, Currency: "CAD"}
+	testPriceUSD := Price{Amount: 15.0, Currency: "USD"}
+	testCostSame := 15.0
+	testCostLow := 10.0
+	testCostHigh := 20.0
+
+	tests := map[string]MarginResult{
+		"no price no cost": {
+			item:     Item{},
+			margin:   0,
+			ok:       false,
+			negative: false,
+		},
+		"price no cost": {
+			item:     Item{Price: &testPrice},
+			margin:   0,
+			ok:       false,
+			negative: false,
+		},
+		"cost no price": {
+			item:     Item{CostInCAD: &testCostLow},
+			margin:   0,
+			ok:       false,
+			negative: false,
+		},
+		"non-CAD price": {
+			item:     Item{Price: &testPriceUSD, CostInCAD: &testCostLow},
+			margin:   0,
+			ok:       false,
+			negative: false,
+		},
+		"positive margin": {
+			item:     Item{Price: &testPrice, CostInCAD: &testCostLow},
+			margin:   5.0,
+			ok:       true,
+			negative: false,
+		},
+		"zero margin": {
+			item:     Item{Price: &testPrice, CostInCAD: &testCostSame},
+			margin:   0,
+			ok:       true,
+			negative: false,
+		},
+		"negative margin": {
+			item:     Item{Price: &testPrice, CostInCAD: &testCostHigh},
+			margin:   -5.0,
+			ok:       true,
+			negative: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			margin, ok := test.item.Margin()
+			if ok != test.ok {
+				t.Errorf("got %v; want %v", ok, test.ok)
+			}
+			if margin != test.margin {
+				t.Errorf("got %v; want %v", margin, test.margin)
+			}
+
+			report := NewMarginReport([]Item{test.item})
+			if len(report.Items) != 1 {
+				t.Fatalf("got %v; want %v", len(report.Items), 1)
+			}
+			if got := report.Items[0].NegativeMargin; got != test.negative {
+				t.Errorf("got %v; want %v", got, test.negative)
+			}
+			if got := report.TotalMargin; got != test.margin {
+				t.Errorf("got %v; want %v", got, test.margin)
+			}
+		})
+	}
+}
+
+func TestComputeMargin(t *testing.T) {
+	testPrice := Price{Amount: 15.0, Currency: "CAD"}
+	testPriceFree := Price{Amount: 0, Currency: "CAD"}
+	testPriceUSD := Price{Amount: 15.0, Currency: "USD"}
+	testCostLow := 10.0
+	testCostHigh := 20.0
+
+	tests := map[string]struct {
+		item    Item
+		amount  float64
+		percent float64
+		present bool
+		hasPct  bool
+	}{
+		"no margin": {
+			item:    Item{Price: &testPriceUSD, CostInCAD: &testCostLow},
+			present: false,
+		},
+		"positive margin rounded": {
+			item:    Item{Price: &testPrice, CostInCAD: &testCostLow},
+			amount:  5.0,
+			percent: 33.33,
+			present: true,
+			hasPct:  true,
+		},
+		"negative margin": {
+			item:    Item{Price: &testPrice, CostInCAD: &testCostHigh},
+			amount:  -5.0,
+			percent: -33.33,
+			present: true,
+			hasPct:  true,
+		},
+		"free item": {
+			item:    Item{Price: &testPriceFree, CostInCAD: &testCostLow},
+			amount:  -10.0,
+			present: true,
+			hasPct:  false,
+		},
+		"stale margin cleared": {
+			item:    Item{UnitMargin: &UnitMargin{AmountInCAD: 1}},
+			present: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.item.ComputeMargin()
+			got := test.item.UnitMargin
+			if present := got != nil; present != test.present {
+				t.Fatalf("got %v; want %v", present, test.present)
+			}
+			if !test.present {
+				return
+			}
+			if got.AmountInCAD != test.amount {
+				t.Errorf("got %v; want %v", got.AmountInCAD, test.amount)
+			}
+			if hasPct := got.Percent != nil; hasPct != test.hasPct {
+				t.Fatalf("got %v; want %v", hasPct, test.hasPct)
+			}
+			if test.hasPct && *got.Percent != test.percent {
+				t.Errorf("got %v; want %v", *got.Percent, test.percent)
+			}
+		})
+	}
+}
+
+func TestNewMarginReportTotal(t *testing.T) {
+	price1, cost1 := Price{Amount: 15.0, Currency: "CAD"}, 10.0
+	price2, cost2 := Price{Amount: 5.0, Currency: "CAD"}, 7.5
+	price3 := Price{Amount: 100.0, Currency: "CAD"}
+
+	items := []Item{
+		{SKU: "AAAAAAAA", Price: &price1, CostInCAD: &cost1},
+		{SKU: "BBBBBBBB", Price: &price2, CostInCAD: &cost2},
+		{SKU: "CCCCCCCC", Price: &price3},
+	}
+
+	report := NewMarginReport(items)
+	if got, want := len(report.Items), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := report.TotalMargin, 2.5; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if report.Items[0].NegativeMargin {
+		t.Error("expected item with price above cost not to be flagged")
+	}
+	if !report.Items[1].NegativeMargin {
+		t.Error("expected item with cost above price to be flagged")
+	}
+	if report.Items[2].Margin != nil {
+		t.Error("expected item with no cost to have no margin")
+	}
+}
+
+func TestNewReorderReport(t *testing.T) {
+	zero, three, five, twenty := 0, 3, 5, 20
+
+	items := []Item{
+		{SKU: "AAAAAAAA", Quantity: &three, ReorderPoint: &five, ReorderQuantity: &twenty},
+		{SKU: "BBBBBBBB", Quantity: &five, ReorderPoint: &five},
+		{SKU: "CCCCCCCC", Quantity: &twenty, ReorderPoint: &five, ReorderQuantity: &twenty},
+		{SKU: "DDDDDDDD", Quantity: &zero},
+		{SKU: "EEEEEEEE", Quantity: &zero, ReorderPoint: &zero, ReorderQuantity: &zero},
+	}
+
+	report := NewReorderReport(items)
+	want := []ReorderEntry{
+		{SKU: "AAAAAAAA", Quantity: 3, ReorderPoint: 5, ReorderQuantity: 20},
+		{SKU: "BBBBBBBB", Quantity: 5, ReorderPoint: 5, ReorderQuantity: 1},
+		{SKU: "EEEEEEEE", Quantity: 0, ReorderPoint: 0, ReorderQuantity: 0},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got %v; want %v", report, want)
+	}
+}
+
+func TestNewItemStats(t *testing.T) {
+	zero, two, three := 0, 2, 3
+	cad := func(amount float64) *Price { return &Price{Amount: amount, Currency: "CAD"} }
+
+	tests := map[string]struct {
+		items []Item
+		want  ItemStats
+	}{
+		"empty": {
+			items: []Item{},
+			want:  ItemStats{Currency: "CAD"},
+		},
+		"priced": {
+			items: []Item{{Price: cad(0.1), Quantity: &three}, {Price: cad(0.2), Quantity: &two}},
+			want:  ItemStats{Count: 2, TotalUnits: 5, TotalValue: 0.7, Currency: "CAD"},
+		},
+		"unpriced and other currency": {
+			items: []Item{{Quantity: &three}, {Price: &Price{Amount: 10, Currency: "USD"}, Quantity: &two}, {Price: cad(1.5), Quantity: &two}},
+			want:  ItemStats{Count: 3, TotalUnits: 7, TotalValue: 3, Currency: "CAD"},
+		},
+		"out of stock": {
+			items: []Item{{Price: cad(5), Quantity: &zero}, {Quantity: &two, Reserved: 2}, {Quantity: &three, Reserved: 2}},
+			want:  ItemStats{Count: 3, TotalUnits: 5, TotalValue: 0, Currency: "CAD", OutOfStock: 2},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := NewItemStats(test.items); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// STOCKTAKE_MAX_SIZE is the maximum number of counts that may be submitted in a single stocktake.
+const STOCKTAKE_MAX_SIZE = 1000
+
+// A Count is the quantity of an Item found by a physical count, identified by its SKU.
+type Count struct {
+	SKU      SKU  `json:"sku"`
+	Quantity *int `json:"quantity"`
+}
+
+// ValidateStocktake checks that between 1 and STOCKTAKE_MAX_SIZE counts are submitted,
+// and that each has a well-formed SKU, counted only once, and a non-negative quantity.
+// SKUs are trimmed, and uppercased if UppercaseSKU is set, as when Items are created.
+// Returns a 400 Bad Request if any count is malformed.
+func ValidateStocktake(counts []Count) (int, error) {
+	if len(counts) == 0 {
+		return http.StatusBadRequest, errors.New("counts are required")
+	}
+	if len(counts) > STOCKTAKE_MAX_SIZE {
+		return http.StatusBadRequest, fmt.Errorf("at most %d counts may be submitted at once", STOCKTAKE_MAX_SIZE)
+	}
+
+	seen := make(map[SKU]bool, len(counts))
+	for i := range counts {
+		item := Item{SKU: counts[i].SKU}
+		if code, err := item.ValidateSKU(); err != nil {
+			return code, fmt.Errorf("count %d: %v", i, err)
+		}
+		counts[i].SKU = item.SKU
+		if seen[item.SKU] {
+			return http.StatusBadRequest, fmt.Errorf("count %d: SKU %v is counted more than once", i, item.SKU)
+		}
+		seen[item.SKU] = true
+
+		if counts[i].Quantity == nil {
+			return http.StatusBadRequest, fmt.Errorf("count %d: quantity is required", i)
+		}
+		if *counts[i].Quantity < 0 {
+			return http.StatusBadRequest, fmt.Errorf("count %d: quantity cannot be negative", i)
+		}
+	}
+	return 0, nil
+}
+
+// A StocktakeChange records the correction made to a single Item's quantity by a stocktake.
+// Delta is the difference between the counted and recorded quantities, negative if stock was missing.
+type StocktakeChange struct {
+	ID          ID  `json:"id"`
+	SKU         SKU `json:"sku"`
+	OldQuantity int `json:"old_quantity"`
+	NewQuantity int `json:"new_quantity"`
+	Delta       int `json:"delta"`
+}
+
+// A StocktakeResult holds the outcome of a stocktake: the Items that were counted, in the order they were submitted,
+// and the SKUs that matched no Item.
+// Items whose count matched their recorded quantity are listed with a Delta of 0.
+type StocktakeResult struct {
+	Counted []StocktakeChange `json:"counted"`
+	Missing []SKU             `json:"missing"`
+}
+
+// NewStocktakeResult creates an empty StocktakeResult.
+func NewStocktakeResult() StocktakeResult {
+	return StocktakeResult{Counted: []StocktakeChange{}, Missing: []SKU{}}
+}
+
+// Add records the count of the Item with the given ID and recorded quantity.
+func (result *StocktakeResult) Add(id ID, count Count, oldQuantity int) {
+	result.Counted = append(result.Counted, StocktakeChange{
+		ID:          id,
+		SKU:         count.SKU,
+		OldQuantity: oldQuantity,
+		NewQuantity: *count.Quantity,
+		Delta:       *count.Quantity - oldQuantity,
+	})
+}
+
+// MissingError returns a 404 Not Found and an error listing the Missing SKUs, or 0 and nil if there are none.
+// An atomic stocktake fails with it rather than applying any counts.
+func (result *StocktakeResult) MissingError() (int, error) {
+	if len(result.Missing) == 0 {
+		return 0, nil
+	}
+	skus := make([]string, len(result.Missing))
+	for i, sku := range result.Missing {
+		skus[i] = string(sku)
+	}
+	return http.StatusNotFound, fmt.Errorf("there are no items with SKUs %s", strings.Join(skus, ", "))
+}
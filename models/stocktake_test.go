@@ -0,0 +1,105 @@
+package models
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateStocktake(t *testing.T) {
+	zero, five, negative := 0, 5, -1
+	tooMany := make([]Count, STOCKTAKE_MAX_SIZE+1)
+	for i := range tooMany {
+		tooMany[i] = Count{SKU: "AAAAAAAA", Quantity: &five}
+	}
+
+	tests := map[string]struct {
+		counts  []Count
+		want    []SKU
+		code    int
+		isError bool
+	}{
+		"valid": {
+			counts:  []Count{{SKU: "AAAAAAAA", Quantity: &five}, {SKU: " BBBBBBBB ", Quantity: &zero}},
+			want:    []SKU{"AAAAAAAA", "BBBBBBBB"},
+			code:    0,
+			isError: false,
+		},
+		"invalid no counts": {
+			counts:  []Count{},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid too many counts": {
+			counts:  tooMany,
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid sku": {
+			counts:  []Count{{SKU: "AA", Quantity: &five}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid duplicate sku": {
+			counts:  []Count{{SKU: "AAAAAAAA", Quantity: &five}, {SKU: "AAAAAAAA ", Quantity: &zero}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid missing quantity": {
+			counts:  []Count{{SKU: "AAAAAAAA"}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+		"invalid negative quantity": {
+			counts:  []Count{{SKU: "AAAAAAAA", Quantity: &negative}},
+			code:    http.StatusBadRequest,
+			isError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, err := ValidateStocktake(test.counts)
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if code != test.code {
+				t.Errorf("got %v; want %v", code, test.code)
+			}
+			if test.isError {
+				return
+			}
+			skus := []SKU{}
+			for _, count := range test.counts {
+				skus = append(skus, count.SKU)
+			}
+			if !reflect.DeepEqual(skus, test.want) {
+				t.Errorf("got %v; want %v", skus, test.want)
+			}
+		})
+	}
+}
+
+func TestStocktakeResult(t *testing.T) {
+	three := 3
+	result := NewStocktakeResult()
+	result.Add("abcdefghijklmnopqrst", Count{SKU: "AAAAAAAA", Quantity: &three}, 5)
+
+	want := []StocktakeChange{{ID: "abcdefghijklmnopqrst", SKU: "AAAAAAAA", OldQuantity: 5, NewQuantity: 3, Delta: -2}}
+	if !reflect.DeepEqual(result.Counted, want) {
+		t.Errorf("got %v; want %v", result.Counted, want)
+	}
+	if code, err := result.MissingError(); err != nil {
+		t.Errorf("got %v; want %v", code, 0)
+	}
+
+	result.Missing = append(result.Missing, "BBBBBBBB", "CCCCCCCC")
+	code, err := result.MissingError()
+	if code != http.StatusNotFound {
+		t.Errorf("got %v; want %v", code, http.StatusNotFound)
+	}
+	if err == nil || !strings.Contains(err.Error(), "BBBBBBBB, CCCCCCCC") {
+		t.Errorf("got %v; want the missing SKUs", err)
+	}
+}
@@ -0,0 +1,28 @@
+package models
+
+import "fmt"
+
+// QUANTITY_WARN_THRESHOLD is the quantity above which an Item's stock is suspiciously high, e.g. a mistyped count.
+const QUANTITY_WARN_THRESHOLD = 1000000
+
+// A Warning describes a value of an Item that is valid, but likely to be a data-entry mistake, e.g. a price of 0.00.
+// Unlike a validation error, it does not stop the Item from being written.
+type Warning string
+
+// Warnings returns the Warnings for a valid Item: a price of 0, a quantity above QUANTITY_WARN_THRESHOLD,
+// or a missing description, in that order.
+// It is only meaningful after ValidateItem, which trims and defaults the Item's fields.
+// Returns an empty slice if there is nothing to warn about.
+func (item *Item) Warnings() []Warning {
+	warnings := []Warning{}
+	if item.Price != nil && item.Price.Amount == 0 {
+		warnings = append(warnings, Warning("price is 0.00"))
+	}
+	if item.Quantity != nil && *item.Quantity > QUANTITY_WARN_THRESHOLD {
+		warnings = append(warnings, Warning(fmt.Sprintf("quantity is above %d", QUANTITY_WARN_THRESHOLD)))
+	}
+	if item.Description == "" {
+		warnings = append(warnings, Warning("description is empty"))
+	}
+	return warnings
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	zero, some, tooMany := 0, 5, QUANTITY_WARN_THRESHOLD+1
+	threshold := QUANTITY_WARN_THRESHOLD
+
+	tests := map[string]struct {
+		item Item
+		want []Warning
+	}{
+		"none": {
+			item: Item{Description: "Some description", Price: &Price{Amount: 9.99, Currency: "CAD"}, Quantity: &some},
+			want: []Warning{},
+		},
+		"no price": {
+			item: Item{Description: "Some description", Quantity: &zero},
+			want: []Warning{},
+		},
+		"quantity at threshold": {
+			item: Item{Description: "Some description", Quantity: &threshold},
+			want: []Warning{},
+		},
+		"zero price": {
+			item: Item{Description: "Some description", Price: &Price{Amount: 0, Currency: "CAD"}, Quantity: &some},
+			want: []Warning{"price is 0.00"},
+		},
+		"high quantity": {
+			item: Item{Description: "Some description", Quantity: &tooMany},
+			want: []Warning{"quantity is above 1000000"},
+		},
+		"missing description": {
+			item: Item{Quantity: &some},
+			want: []Warning{"description is empty"},
+		},
+		"all": {
+			item: Item{Price: &Price{Amount: 0, Currency: "USD"}, Quantity: &tooMany},
+			want: []Warning{"price is 0.00", "quantity is above 1000000", "description is empty"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := test.item.Warnings()
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// A Role is the level of access granted to an API key.
+type Role string
+
+const (
+	// RoleReadOnly may only read data.
+	RoleReadOnly Role = "read-only"
+	// RoleReadWrite may read and change data.
+	RoleReadWrite Role = "read-write"
+)
+
+// Allows returns true if the Role may make the request, false otherwise.
+func (role Role) Allows(r *http.Request) bool {
+	return role == RoleReadWrite || (role == RoleReadOnly && isRead(r))
+}
+
+// roleKey is the context key under which Authenticate stores the Role of the request's API key.
+type roleKey struct{}
+
+// RoleFromContext returns the Role of the API key that authenticated a request and true,
+// or the empty Role and false if the request was not authenticated with an API key.
+func RoleFromContext(ctx context.Context) (Role, bool) {
+	role, ok := ctx.Value(roleKey{}).(Role)
+	return role, ok
+}
+
+// Authenticate is middleware that requires every request to carry one of the Server's API keys
+// as a bearer token, i.e. with the "Authorization: Bearer <key>" header, and that the key's Role allows the request.
+// The Role is attached to the request's context, from which it can be read with RoleFromContext.
+// The API is open to all if the Server has no API keys. Reads are also left open if the Server is configured
+// with PublicReads. Admin endpoints are left to check the admin API key themselves.
+//
+// Returns a 401 Unauthorized if the request does not carry an API key.
+// Returns a 403 Forbidden if the API key is not one of the Server's, or if its Role does not allow the request.
+func (s *Server) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.config.hasAPIKeys() || isAdminPath(r, s.config.BasePath) || (s.config.PublicReads && isRead(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, ok := bearerToken(r)
+		if !ok {
+			s.setHeader(w)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, r, http.StatusUnauthorized, errors.New("missing API key"))
+			return
+		}
+		role, ok := s.config.roleOf(key)
+		if !ok {
+			s.setHeader(w)
+			writeError(w, r, http.StatusForbidden, errors.New("invalid API key"))
+			return
+		}
+		if !role.Allows(r) {
+			s.setHeader(w)
+			writeError(w, r, http.StatusForbidden, errors.New("API key is read-only"))
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleKey{}, role)))
+	})
+}
+
+// hasAPIKeys returns true if the Config has any API keys, false otherwise.
+func (c *Config) hasAPIKeys() bool {
+	return len(c.APIKeys) > 0 || len(c.ReadOnlyAPIKeys) > 0
+}
+
+// roleOf returns the Role of the API key and true, or the empty Role and false if it is not one of the Config's keys.
+// A key that is both read-write and read-only is read-write.
+func (c *Config) roleOf(key string) (Role, bool) {
+	if matchesAny(key, c.APIKeys) {
+		return RoleReadWrite, true
+	}
+	if matchesAny(key, c.ReadOnlyAPIKeys) {
+		return RoleReadOnly, true
+	}
+	return "", false
+}
+
+// bearerToken returns the bearer token of the request's Authorization header and true,
+// or the empty string and false if there is none.
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(header, "Bearer "), true
+}
+
+// matchesAny returns true if the key is one of the keys, false otherwise.
+// Every key is compared in constant time, so that the time taken reveals neither the keys nor which one matched.
+func matchesAny(key string, keys []string) bool {
+	match := 0
+	for _, k := range keys {
+		match |= subtle.ConstantTimeCompare([]byte(key), []byte(k))
+	}
+	return match == 1
+}
+
+// readPaths are the endpoints that only read data despite being POSTs, because their requests have a body.
+// They are relative to the base path under which the API is registered.
+var readPaths = []string{
+	"/items/batch-get",
+	"/items/history/batch",
+}
+
+// isRead returns true if the request only reads data, i.e. is a GET or HEAD or is for one of the readPaths,
+// false otherwise.
+func isRead(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	if r.Method != http.MethodPost {
+		return false
+	}
+	path := routePath(r)
+	for _, readPath := range readPaths {
+		if strings.HasSuffix(path, readPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdminPath returns true if the request is for an admin endpoint under the base path, e.g. "/api/admin/import", false otherwise.
+// Other endpoints whose paths merely contain "/admin/", e.g. an Item whose ID is "admin", are not admin endpoints.
+func isAdminPath(r *http.Request, basePath string) bool {
+	return strings.HasPrefix(routePath(r), basePath+"/admin/")
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lbisceglia/shopify/models"
+)
+
+// A Config holds the settings of a Server.
+type Config struct {
+	// AdminAPIKey is the key required to access admin endpoints.
+	// Admin endpoints are disabled if it is empty.
+	AdminAPIKey string
+
+	// APIKeys are the read-write keys, any one of which a client may send as a bearer token to use the API.
+	// The API is open to all if there are no APIKeys or ReadOnlyAPIKeys.
+	APIKeys []string
+
+	// ReadOnlyAPIKeys are keys that may only be used to read data.
+	ReadOnlyAPIKeys []string
+
+	// PublicReads leaves reads open to clients without an API key.
+	// It has no effect if there are no APIKeys or ReadOnlyAPIKeys.
+	PublicReads bool
+
+	// EnableMaintenance enables the admin database maintenance endpoints.
+	// Maintenance is specific to the PostgreSQL backend, so it is disabled by default.
+	EnableMaintenance bool
+
+	// RequireAlphanumericSKU rejects SKUs made up only of hyphens and underscores.
+	// It is disabled by default so that existing SKUs remain valid.
+	RequireAlphanumericSKU bool
+
+	// UppercaseSKU canonicalizes SKUs to uppercase on input.
+	// It is disabled by default so that existing SKUs remain distinct.
+	UppercaseSKU bool
+
+	// UniqueNames rejects Items whose name is already in use by another Item.
+	// It is disabled by default so that stores that allow duplicate names are not affected.
+	// The database reads the same UNIQUE_NAMES setting to maintain its unique index on names.
+	UniqueNames bool
+
+	// UniqueBarcodes rejects Items whose barcode is already in use by another Item.
+	// It is disabled by default so that variants sold under a single barcode are not affected.
+	// The database reads the same UNIQUE_BARCODES setting to maintain its unique index on barcodes.
+	UniqueBarcodes bool
+
+	// EnforceReservedStock rejects changes to an Item's quantity that would leave
+	// more stock reserved than is in inventory.
+	EnforceReservedStock bool
+
+	// StrictQuantity rejects Items that are created or updated without a quantity, rather than giving them a quantity of 0.
+	// It is disabled by default so that existing clients that omit the quantity are not affected.
+	StrictQuantity bool
+
+	// SKUMinLen and SKUMaxLen bound the length of SKUs.
+	// They are models.SKU_MIN_LEN and models.SKU_MAX_LEN by default.
+	SKUMinLen int
+	SKUMaxLen int
+
+	// NameMaxLen and DescriptionMaxLen bound the length of names and descriptions, in characters.
+	// They are models.NAME_MAX_LEN and models.DESCRIPTION_MAX_LEN by default.
+	NameMaxLen        int
+	DescriptionMaxLen int
+
+	// IDFormat is the format in which Item IDs are created and validated.
+	// It is models.XID_FORMAT by default.
+	IDFormat models.IDFormat
+
+	// DefaultCurrency is the ISO-4217 code of the currency that the store prices its Items in.
+	// It is models.LEGACY_CURRENCY (CAD) by default.
+	DefaultCurrency string
+
+	// WebhookURL is the URL to which Events are posted when Items change.
+	// No Events are sent if it is empty.
+	WebhookURL string
+
+	// BasePath is the path under which the API's routes are registered, e.g. "/api/v1".
+	// It is DEFAULT_BASE_PATH by default.
+	BasePath string
+
+	// VerboseErrors includes the detail of conflicts in error responses, e.g. the SKU that is already in use.
+	// If it is false, clients are told only which field conflicts, and the detail is logged instead,
+	// so that the API does not disclose which SKUs, names, and barcodes exist.
+	// It is enabled by default so that existing clients are not affected.
+	VerboseErrors bool
+
+	// MaxBodyBytes is the largest request body, in bytes, that the Server will read.
+	// If it is not positive, DEFAULT_MAX_BODY_BYTES is used.
+	MaxBodyBytes int64
+}
+
+// DEFAULT_MAX_BODY_BYTES is the default largest request body, 1MB.
+const DEFAULT_MAX_BODY_BYTES = 1 << 20
+
+// NewConfig creates a Config from the environment.
+func NewConfig() Config {
+	skuMinLen, skuMaxLen := envSKULengths("SKU_MIN_LEN", "SKU_MAX_LEN")
+	defaultCurrency, err := DefaultCurrency()
+	if err != nil {
+		slog.Warn("unknown default currency; using the legacy currency", "error", err, "default", defaultCurrency)
+	}
+	return Config{
+		AdminAPIKey:            os.Getenv("ADMIN_API_KEY"),
+		APIKeys:                envList("API_KEY"),
+		ReadOnlyAPIKeys:        envList("READ_ONLY_API_KEY"),
+		PublicReads:            envBool("PUBLIC_READS"),
+		EnableMaintenance:      envBool("ENABLE_MAINTENANCE"),
+		RequireAlphanumericSKU: envBool("REQUIRE_ALPHANUMERIC_SKU"),
+		UppercaseSKU:           envBool("SKU_UPPERCASE"),
+		UniqueNames:            envBool("UNIQUE_NAMES"),
+		UniqueBarcodes:         envBool("UNIQUE_BARCODES"),
+		EnforceReservedStock:   envBool("ENFORCE_RESERVED_STOCK"),
+		StrictQuantity:         envBool("STRICT_QUANTITY"),
+		SKUMinLen:              skuMinLen,
+		SKUMaxLen:              skuMaxLen,
+		NameMaxLen:             int(envInt64("NAME_MAX_LEN", models.NAME_MAX_LEN)),
+		DescriptionMaxLen:      int(envInt64("DESCRIPTION_MAX_LEN", models.DESCRIPTION_MAX_LEN)),
+		IDFormat:               envIDFormat("ID_FORMAT"),
+		DefaultCurrency:        defaultCurrency,
+		WebhookURL:             os.Getenv("WEBHOOK_URL"),
+		BasePath:               envBasePath("BASE_PATH"),
+		VerboseErrors:          envBoolDefault("VERBOSE_ERRORS", true),
+		MaxBodyBytes:           envInt64("MAX_BODY_BYTES", DEFAULT_MAX_BODY_BYTES),
+	}
+}
+
+// envBool reads a boolean from the environment.
+// Returns false if the variable is unset or cannot be parsed.
+func envBool(key string) bool {
+	b, _ := strconv.ParseBool(os.Getenv(key))
+	return b
+}
+
+// envBoolDefault reads a boolean from the environment.
+// Returns the default if the variable is unset or cannot be parsed.
+func envBoolDefault(key string, def bool) bool {
+	b, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// envList reads a comma-separated list from the environment, ignoring blank entries.
+// Returns nil if the variable is unset or has no entries.
+func envList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// envIDFormat reads an IDFormat from the environment.
+// Returns models.XID_FORMAT if the variable is unset or is not a known IDFormat.
+func envIDFormat(key string) models.IDFormat {
+	format, err := models.ParseIDFormat(os.Getenv(key))
+	if err != nil {
+		slog.Warn("invalid ID format; using the default", "key", key, "error", err, "default", models.XID_FORMAT)
+		return models.XID_FORMAT
+	}
+	return format
+}
+
+// DefaultCurrency reads the currency that the store prices its Items in from DEFAULT_CURRENCY, as in envCurrency,
+// so that the server can refuse to start with an unknown currency rather than price Items in the wrong one.
+func DefaultCurrency() (string, error) {
+	return envCurrency("DEFAULT_CURRENCY")
+}
+
+// envCurrency reads an ISO-4217 currency code from the environment, in any case.
+// Returns models.LEGACY_CURRENCY and nil if the variable is unset.
+// Returns models.LEGACY_CURRENCY and an error if the variable is not a known currency code.
+func envCurrency(key string) (string, error) {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return models.LEGACY_CURRENCY, nil
+	}
+	code := strings.ToUpper(v)
+	if !models.IsCurrency(code) {
+		return models.LEGACY_CURRENCY, fmt.Errorf("%s %q is not a known ISO-4217 currency code", key, v)
+	}
+	return code, nil
+}
+
+// envSKULengths reads the bounds of the length of SKUs from the environment.
+// Each bound is its default if its variable is unset or is not a positive integer.
+// Returns models.SKU_MIN_LEN and models.SKU_MAX_LEN if the minimum would exceed the maximum.
+func envSKULengths(minKey, maxKey string) (int, int) {
+	min := int(envInt64(minKey, models.SKU_MIN_LEN))
+	max := int(envInt64(maxKey, models.SKU_MAX_LEN))
+	if min > max {
+		slog.Warn("minimum SKU length exceeds the maximum; using the defaults", minKey, min, maxKey, max, "default_min", models.SKU_MIN_LEN, "default_max", models.SKU_MAX_LEN)
+		return models.SKU_MIN_LEN, models.SKU_MAX_LEN
+	}
+	return min, max
+}
+
+// envBasePath reads a base path from the environment and formats it as a prefix for RegisterRoutes,
+// e.g. "api/v1/" becomes "/api/v1", and "/" registers the API at the root.
+// Returns DEFAULT_BASE_PATH if the variable is unset or contains route variables or query characters.
+func envBasePath(key string) string {
+	v := os.Getenv(key)
+	if v == "" {
+		return DEFAULT_BASE_PATH
+	}
+	if strings.ContainsAny(v, "{}?#") {
+		slog.Warn("invalid base path; using the default", "key", key, "value", v, "default", DEFAULT_BASE_PATH)
+		return DEFAULT_BASE_PATH
+	}
+	return normalizeBasePath(v)
+}
+
+// envInt64 reads a positive integer from the environment.
+// Returns the default if the variable is unset or is not a positive integer.
+func envInt64(key string, def int64) int64 {
+	n, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GZIP_THRESHOLD is the size in bytes that a response body must exceed to be compressed.
+// Smaller bodies are not worth the overhead of compressing them.
+const GZIP_THRESHOLD = 1024
+
+// Gzip is middleware that compresses a response with gzip if the client accepts it
+// and the body exceeds GZIP_THRESHOLD bytes. Compressed responses have the Content-Encoding: gzip header
+// and no Content-Length. Responses without a body, e.g. 204 No Content, are left untouched.
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipWriter{ResponseWriter: w, status: http.StatusOK}
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip returns true if the request's Accept-Encoding headers list gzip without a zero quality,
+// false otherwise.
+func acceptsGzip(r *http.Request) bool {
+	for _, header := range r.Header.Values("Accept-Encoding") {
+		for _, encoding := range strings.Split(header, ",") {
+			parts := strings.Split(encoding, ";")
+			if !strings.EqualFold(strings.TrimSpace(parts[0]), "gzip") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q == 0 {
+						return false
+					}
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// A gzipWriter is a ResponseWriter that holds back the start of the response until it knows
+// whether the body exceeds GZIP_THRESHOLD bytes, and then writes it either compressed or as is.
+type gzipWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	buf         []byte
+	status      int
+	wroteHeader bool
+	decided     bool
+}
+
+// WriteHeader records the status code. It is only written to the response once the body is known
+// to need compressing or not.
+func (gw *gzipWriter) WriteHeader(code int) {
+	if gw.wroteHeader {
+		return
+	}
+	gw.status = code
+	gw.wroteHeader = true
+}
+
+// Write buffers the body until it exceeds GZIP_THRESHOLD bytes, at which point the response is started
+// compressed. Once started, the body is written straight through.
+func (gw *gzipWriter) Write(b []byte) (int, error) {
+	if !gw.decided {
+		gw.buf = append(gw.buf, b...)
+		if len(gw.buf) <= GZIP_THRESHOLD {
+			return len(b), nil
+		}
+		if err := gw.start(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if gw.gz != nil {
+		return gw.gz.Write(b)
+	}
+	return gw.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client, if the underlying ResponseWriter supports it.
+// A body flushed before it exceeds GZIP_THRESHOLD bytes is sent uncompressed.
+func (gw *gzipWriter) Flush() {
+	if !gw.decided {
+		gw.start(false)
+	}
+	if gw.gz != nil {
+		gw.gz.Flush()
+	}
+	if flusher, ok := gw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close ends the response, sending any buffered body uncompressed or finishing the compressed stream.
+func (gw *gzipWriter) Close() error {
+	if !gw.decided {
+		return gw.start(false)
+	}
+	if gw.gz != nil {
+		return gw.gz.Close()
+	}
+	return nil
+}
+
+// start writes the status code and headers, followed by the buffered body, compressed or not.
+// Responses that already have a Content-Encoding are never compressed again.
+func (gw *gzipWriter) start(compress bool) error {
+	gw.decided = true
+	header := gw.ResponseWriter.Header()
+	if compress && header.Get("Content-Encoding") == "" {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		// The compressed body is no longer byte-for-byte the same as the one the ETag was computed from,
+		// unless the ETag is an Item's, which is computed from its version instead
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			if _, ok := parseItemETag(etag); !ok {
+				header.Set("ETag", "W/"+etag)
+			}
+		}
+		gw.gz = gzip.NewWriter(gw.ResponseWriter)
+	}
+	gw.ResponseWriter.WriteHeader(gw.status)
+
+	buf := gw.buf
+	gw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if gw.gz != nil {
+		_, err := gw.gz.Write(buf)
+		return err
+	}
+	_, err := gw.ResponseWriter.Write(buf)
+	return err
+}
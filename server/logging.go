@@ -0,0 +1,38 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// The formats of the log that may be selected with LOG_FORMAT.
+const (
+	TEXT_LOG_FORMAT = "text" // key=value pairs, e.g. `level=INFO msg="applied database migration"`; the default
+	JSON_LOG_FORMAT = "json" // one json object per record, for log aggregators to index
+)
+
+// NewLogger creates the logger that the service writes its records to, e.g. os.Stderr, as configured by the environment.
+// LOG_FORMAT selects the format of the records, TEXT_LOG_FORMAT by default, and LOG_LEVEL the least severe level logged,
+// one of debug, info, warn, or error, info by default.
+// Returns the logger and nil if successful, otherwise nil and an error if either variable is set to an unknown value.
+func NewLogger(w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if v := strings.TrimSpace(os.Getenv("LOG_LEVEL")); v != "" {
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			return nil, errors.New("LOG_LEVEL must be debug, info, warn, or error")
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))) {
+	case "", TEXT_LOG_FORMAT:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case JSON_LOG_FORMAT:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, errors.New("LOG_FORMAT must be text or json")
+	}
+}
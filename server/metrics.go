@@ -0,0 +1,113 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lbisceglia/shopify/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics holds the Prometheus metrics recorded by a Server.
+// Each Server has its own registry so that several Servers, e.g. in tests, do not collide.
+type metrics struct {
+	registry  *prometheus.Registry
+	requests  *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	itemCount prometheus.Gauge
+}
+
+// newMetrics creates and registers the metrics recorded by a Server,
+// along with the standard Go runtime and process metrics.
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of HTTP requests handled, by method, route, and status code.",
+		}, []string{"method", "path", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of HTTP requests, by method and route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "path"}),
+		itemCount: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "inventory_items",
+			Help: "Number of items in inventory.",
+		}),
+	}
+	m.registry.MustRegister(
+		m.requests,
+		m.latency,
+		m.itemCount,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Instrument is middleware that records the count and latency of every request handled by the router.
+// Requests are labelled with their route template, e.g. "/api/items/{id}", rather than their URL,
+// so that the number of series does not grow with the number of Items.
+// Every request is also logged at the info level, with its method, route, status code, latency, and request ID.
+func (s *Server) Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		path := routePath(r)
+		elapsed := time.Since(start)
+		s.metrics.requests.WithLabelValues(r.Method, path, strconv.Itoa(rec.status)).Inc()
+		s.metrics.latency.WithLabelValues(r.Method, path).Observe(elapsed.Seconds())
+		requestLog(r.Context()).Info("served request", "method", r.Method, "path", path, "status", rec.status,
+			"duration_ms", float64(elapsed)/float64(time.Millisecond))
+	})
+}
+
+// Metrics responds with the Server's metrics in the Prometheus exposition format.
+// The item count is refreshed from the database on every scrape; if it cannot be counted,
+// the previous count is reported.
+//
+// Returns the metrics and a 200 OK on success.
+func (s *Server) Metrics(w http.ResponseWriter, r *http.Request) {
+	if count, _, err := s.db.CountItems(r.Context(), &models.Filter{}); err != nil {
+		requestLog(r.Context()).Error("cannot count items", "error", err)
+	} else {
+		s.metrics.itemCount.Set(float64(count))
+	}
+	promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// A statusRecorder is a ResponseWriter that records the status code written to the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code and writes it to the response.
+func (rec *statusRecorder) WriteHeader(code int) {
+	if !rec.wroteHeader {
+		rec.status = code
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the body to the response, which starts it with a 200 OK if no status code was written.
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client, if the underlying ResponseWriter supports it,
+// so that streamed responses are not held back by the middleware.
+func (rec *statusRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// NAMING_PARAM is the query parameter that selects the naming convention of the fields in a json response.
+const NAMING_PARAM = "naming"
+
+// The naming conventions that may be selected with NAMING_PARAM.
+const (
+	SNAKE_NAMING = "snake" // the API's own names, e.g. "date_added" and "price_CAD"; the default
+	CAMEL_NAMING = "camel" // camelCase names, e.g. "dateAdded" and "priceCAD"
+)
+
+// Naming is middleware that renames the fields of json responses to camelCase, e.g. "reorder_point" becomes "reorderPoint",
+// if the request selects it with the naming query parameter, e.g. "?naming=camel", for clients that expect camelCase.
+// Responses are unchanged by default or with "?naming=snake", so existing clients see no change.
+// Only the keys of json objects are renamed, never their values, in every json and newline-delimited json response.
+// Keys that are data rather than field names, e.g. the IDs of Items, never contain underscores and so are never renamed.
+// A camelCase response is held back until the handler has finished writing it, so that it can be rewritten in full;
+// streamed responses are therefore sent all at once.
+// Returns a 400 Bad Request if the naming convention is not known.
+func Naming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get(NAMING_PARAM) {
+		case "", SNAKE_NAMING:
+			next.ServeHTTP(w, r)
+			return
+		case CAMEL_NAMING:
+		default:
+			writeError(w, r, http.StatusBadRequest, errors.New("naming must be snake or camel"))
+			return
+		}
+
+		cw := &camelWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(cw, r)
+		cw.Close()
+	})
+}
+
+// A camelWriter is a ResponseWriter that buffers a response so that its json can be rewritten with camelCase keys
+// once the handler has finished writing it.
+type camelWriter struct {
+	http.ResponseWriter
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code. It is only written to the response once the body has been rewritten.
+func (cw *camelWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.status = code
+	cw.wroteHeader = true
+}
+
+// Write buffers the body.
+func (cw *camelWriter) Write(b []byte) (int, error) {
+	cw.wroteHeader = true
+	return cw.buf.Write(b)
+}
+
+// Close rewrites a json body with camelCase keys and writes the response.
+// Bodies that are not json, e.g. plain text errors, and json that cannot be parsed, are written as they are.
+func (cw *camelWriter) Close() {
+	body := cw.buf.Bytes()
+	if isJSONMediaType(cw.Header().Get("Content-Type")) {
+		if rewritten, err := camelCaseJSON(body); err == nil {
+			body = rewritten
+		}
+	}
+	cw.Header().Del("Content-Length")
+	cw.ResponseWriter.WriteHeader(cw.status)
+	cw.ResponseWriter.Write(body)
+}
+
+// isJSONMediaType returns true if the Content-Type is json, newline-delimited json, or a json-based media type
+// such as ENVELOPE_MEDIA_TYPE, false otherwise.
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || mediaType == NDJSON_MEDIA_TYPE || strings.HasSuffix(mediaType, "+json")
+}
+
+// camelCaseJSON rewrites every json value in the body, e.g. each line of newline-delimited json,
+// with the keys of its objects in camelCase. The order of keys is kept, and each value is followed by a newline.
+// Returns the rewritten body and nil if successful, otherwise nil and an error.
+func camelCaseJSON(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var out bytes.Buffer
+	for {
+		if err := rewriteValue(decoder, &out); err == io.EOF {
+			return out.Bytes(), nil
+		} else if err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+	}
+}
+
+// rewriteValue copies the next json value from the decoder to the buffer, with the keys of its objects in camelCase.
+// Returns io.EOF if there are no more values.
+func rewriteValue(decoder *json.Decoder, out *bytes.Buffer) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		b, err := json.Marshal(token)
+		if err != nil {
+			return err
+		}
+		out.Write(b)
+		return nil
+	}
+
+	out.WriteRune(rune(delim))
+	for i := 0; decoder.More(); i++ {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		if delim == '{' {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			b, err := json.Marshal(camelCase(key.(string)))
+			if err != nil {
+				return err
+			}
+			out.Write(b)
+			out.WriteByte(':')
+		}
+		if err := rewriteValue(decoder, out); err != nil {
+			return unexpectedEOF(err)
+		}
+	}
+	end, err := decoder.Token()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	out.WriteRune(rune(end.(json.Delim)))
+	return nil
+}
+
+// unexpectedEOF reports the end of the body within a json value as io.ErrUnexpectedEOF,
+// so that it is not mistaken for the end of the values.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// camelCase converts a snake_case name to camelCase, e.g. "date_added" becomes "dateAdded".
+// The first letter of each word after the first is uppercased and the rest of the name is kept as is,
+// e.g. "price_CAD" becomes "priceCAD". Names without underscores are unchanged.
+func camelCase(name string) string {
+	words := strings.Split(name, "_")
+	for i := 1; i < len(words); i++ {
+		if words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	return strings.Join(words, "")
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the OpenAPI 3 description of the API. It is maintained by hand alongside API.md,
+// and must describe exactly the routes registered by RegisterRoutes under DEFAULT_BASE_PATH.
+// Its ItemInput schema is also the schema that Items in request bodies are validated against.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPI responds with the OpenAPI 3 description of the API, for generating clients and documentation.
+//
+// Returns the OpenAPI document and a 200 OK.
+func (s *Server) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(openAPISpec); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
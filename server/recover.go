@@ -0,0 +1,32 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover is middleware that catches a panic in a handler, logs it along with its stack trace,
+// and responds with a 500 Internal Server Error, so that a bug in one handler does not drop the connection.
+// If the handler has already started its response, the response can only be cut short.
+// An http.ErrAbortHandler panic is passed on, since it is meant to abort the response.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			requestLog(r.Context()).Error("panic serving request", "method", r.Method, "path", r.URL.Path, "panic", err, "stack", string(debug.Stack()))
+			if rec.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			writeError(rec, r, http.StatusInternalServerError, errors.New("internal server error"))
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
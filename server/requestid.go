@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/lbisceglia/shopify/models"
+)
+
+// REQUEST_ID_HEADER is the header that carries the ID of a request, in the request and in its response.
+const REQUEST_ID_HEADER = "X-Request-ID"
+
+// REQUEST_ID_MAX_LEN is the longest request ID accepted from a client; longer IDs are replaced.
+const REQUEST_ID_MAX_LEN = 128
+
+// requestIDKey is the context key under which the ID of a request is stored.
+type requestIDKey struct{}
+
+// RequestID is middleware that identifies every request, so that its log lines can be traced across services.
+// The ID is taken from the request's X-Request-ID header, e.g. one set by a load balancer,
+// or a new ID is generated if the header is missing or not a valid ID.
+// The ID is stored in the request's context, for handlers to read with RequestIDFromContext,
+// and is echoed in the response's X-Request-ID header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(REQUEST_ID_HEADER)
+		if !validRequestID(id) {
+			id = string(models.NewID())
+		}
+		w.Header().Set(REQUEST_ID_HEADER, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFromContext returns the ID of the request that the context belongs to,
+// or the empty string if the context does not belong to a request identified by RequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID returns true if the ID is non-empty, at most REQUEST_ID_MAX_LEN characters,
+// and made up only of printable ASCII characters, so that it is safe to log and echo, false otherwise.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > REQUEST_ID_MAX_LEN {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestLog returns a logger whose records carry the ID of the request that the context belongs to
+// as their request_id attribute, so that every record logged while serving a request can be traced to it.
+// Returns the default logger if the context does not belong to an identified request.
+func requestLog(ctx context.Context) *slog.Logger {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return slog.Default()
+	}
+	return slog.Default().With("request_id", id)
+}
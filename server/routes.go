@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// DEFAULT_BASE_PATH is the path under which the API's routes are registered by default, e.g. "/api/items".
+const DEFAULT_BASE_PATH = "/api"
+
+// Routes creates the router that serves the Server in production and in tests alike.
+// The routes are registered by RegisterRoutes under the BASE_PATH read from the environment, DEFAULT_BASE_PATH by default,
+// and every request passes through the Server's middleware: request IDs, metrics, compression, field naming, panic recovery, and authentication.
+// A request whose path is routed, but not for its method, is answered by MethodNotAllowed.
+func Routes(s InventoryServer) *mux.Router {
+	r := mux.NewRouter().StrictSlash(true)
+	RegisterRoutes(r, NewConfig().BasePath, s)
+	r.MethodNotAllowedHandler = RequestID(MethodNotAllowed(r))
+	r.Use(RequestID, s.Instrument, Gzip, Naming, Recover, s.Authenticate)
+	return r
+}
+
+// RegisterRoutes registers the Server's handlers on the router.
+// The API's routes are registered on a subrouter under the prefix, e.g. "/api/v1" serves "/api/v1/items",
+// so that another version of the API can later be registered alongside it under its own prefix.
+// The operational routes, /metrics, /openapi.json, and /version, are registered at the root of the router.
+// An empty prefix registers the API's routes at the root as well.
+func RegisterRoutes(r *mux.Router, prefix string, s InventoryServer) {
+	api := r
+	if prefix != "" {
+		api = r.PathPrefix(prefix).Subrouter()
+	}
+	api.HandleFunc("/items/tags", s.GetTags).Methods(http.MethodGet)
+	api.HandleFunc("/items/deleted", s.GetDeletedItems).Methods(http.MethodGet)
+	api.HandleFunc("/items/stream", s.StreamItems).Methods(http.MethodGet)
+	api.HandleFunc("/items/sku/{sku}", s.GetItemBySKU).Methods(http.MethodGet)
+	api.HandleFunc("/items/barcode/{code}", s.GetItemByBarcode).Methods(http.MethodGet)
+	api.HandleFunc("/items/search", s.SearchItems).Methods(http.MethodGet)
+	api.HandleFunc("/items/reorder", s.GetReorderReport).Methods(http.MethodGet)
+	api.HandleFunc("/items/stats", s.GetItemStats).Methods(http.MethodGet)
+	api.HandleFunc("/items/history/batch", s.GetItemHistories).Methods(http.MethodPost)
+	api.HandleFunc("/items/batch-get", s.GetItemsByIDs).Methods(http.MethodPost)
+	api.HandleFunc("/items/stocktake", s.Stocktake).Methods(http.MethodPost)
+	api.HandleFunc("/items/transfer", s.Transfer).Methods(http.MethodPost)
+	api.HandleFunc("/items/bulk", s.UpdateItems).Methods(http.MethodPut)
+	api.HandleFunc("/items", s.CreateItem).Methods(http.MethodPost)
+	api.HandleFunc("/items/{id}", s.UpdateItem).Methods(http.MethodPut)
+	api.HandleFunc("/items/{id}", s.PatchItem).Methods(http.MethodPatch)
+	api.HandleFunc("/items/{id}", s.DeleteItem).Methods(http.MethodDelete)
+	api.HandleFunc("/items", s.DeleteItems).Methods(http.MethodDelete)
+	api.HandleFunc("/items", s.GetItems).Methods(http.MethodGet)
+	api.HandleFunc("/items/{id}", s.GetItem).Methods(http.MethodGet)
+	api.HandleFunc("/items/{id}/adjust", s.AdjustQuantity).Methods(http.MethodPost)
+	api.HandleFunc("/items/{id}/increment", s.Increment).Methods(http.MethodPost)
+	api.HandleFunc("/items/{id}/decrement", s.Decrement).Methods(http.MethodPost)
+	api.HandleFunc("/items/{id}/history", s.GetItemHistory).Methods(http.MethodGet)
+	api.HandleFunc("/items/{id}/reserve", s.Reserve).Methods(http.MethodPost)
+	api.HandleFunc("/items/{id}/release", s.Release).Methods(http.MethodPost)
+	api.HandleFunc("/reports/margin", s.GetMarginReport).Methods(http.MethodGet)
+	api.HandleFunc("/admin/import", s.ImportItems).Methods(http.MethodPost)
+	api.HandleFunc("/admin/sku-normalization/preview", s.PreviewSKUNormalization).Methods(http.MethodGet)
+	api.HandleFunc("/admin/maintenance/analyze", s.Analyze).Methods(http.MethodPost)
+
+	r.HandleFunc("/metrics", s.Metrics).Methods(http.MethodGet)
+	r.HandleFunc("/openapi.json", s.OpenAPI).Methods(http.MethodGet)
+	r.HandleFunc("/version", s.Version).Methods(http.MethodGet)
+}
+
+// allowableMethods are the methods that MethodNotAllowed may list in the Allow header, in the order they are listed.
+var allowableMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// MethodNotAllowed returns the handler for requests to a path that the router serves, but not with the request's method,
+// e.g. POST /api/items/{id}. It responds with a 405 Method Not Allowed, as a json error like any other,
+// and lists the methods that the path is served with in the Allow header, e.g. "GET, PUT, PATCH, DELETE".
+// gorilla/mux does not run the router's middleware for such requests.
+func MethodNotAllowed(r *mux.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		allowed := allowedMethods(r, req)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, req, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed; use %s", req.Method, strings.Join(allowed, ", ")))
+	})
+}
+
+// allowedMethods returns the allowableMethods with which the router would serve the request's path.
+func allowedMethods(r *mux.Router, req *http.Request) []string {
+	allowed := []string{}
+	for _, method := range allowableMethods {
+		probe := req.Clone(req.Context())
+		probe.Method = method
+		var match mux.RouteMatch
+		if r.Match(probe, &match) && match.MatchErr == nil {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// routePath returns the path template of the route that matched the request, e.g. "/api/items/{id}",
+// or the request's path if no route matched.
+func routePath(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return r.URL.Path
+}
+
+// normalizeBasePath formats a base path as a prefix for RegisterRoutes, e.g. "api/v1/" becomes "/api/v1".
+// Returns the empty string for the root path.
+func normalizeBasePath(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lbisceglia/shopify/models"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// openAPISpecURL is the URL under which openAPISpec is given to the schema compiler.
+// It is never fetched; it only resolves the references between the spec's schemas.
+const openAPISpecURL = "openapi.json"
+
+// itemSchemaPointer locates the schema of an Item in a request body within openAPISpec.
+const itemSchemaPointer = "#/components/schemas/ItemInput"
+
+// publishedItemSchema is the schema compiled by compileItemSchema, once for every Server,
+// or nil along with the error if it cannot be compiled.
+var publishedItemSchema, itemSchemaErr = compileItemSchema()
+
+// stringKeywords are the schema keywords that constrain the content of strings.
+// Strings are trimmed, and SKUs may be uppercased, before the Item's own validators check them,
+// and some bounds, e.g. NAME_MAX_LEN, are configurable, so only those validators may enforce these.
+var stringKeywords = []string{"minLength", "maxLength", "pattern", "format"}
+
+// compileItemSchema compiles the JSON Schema that Items in request bodies are validated against.
+// It is taken from openAPISpec, so that requests are held to the same schema that is published to clients,
+// except for the stringKeywords, which are left to the Item's own validators.
+// The schema checks the shape of a request: that its values have the right types, that required fields are present,
+// and that numbers and lists are within bounds.
+// Returns the schema and nil if successful, otherwise nil and an error.
+func compileItemSchema() (*jsonschema.Schema, error) {
+	var spec map[string]interface{}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		return nil, err
+	}
+	schemas, ok := lookup(spec, "components", "schemas").(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s has no schemas", openAPISpecURL)
+	}
+	for _, schema := range schemas {
+		removeKeywords(schema, stringKeywords)
+	}
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	if err := compiler.AddResource(openAPISpecURL, bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(openAPISpecURL + itemSchemaPointer)
+}
+
+// lookup returns the value at the path of keys within a decoded json document, or nil if there is none.
+func lookup(doc interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		doc = m[key]
+	}
+	return doc
+}
+
+// removeKeywords removes the keywords from the schema and every schema nested within it.
+// Properties that share a keyword's name are kept, as their values are schemas rather than keyword values.
+func removeKeywords(schema interface{}, keywords []string) {
+	switch v := schema.(type) {
+	case map[string]interface{}:
+		for _, keyword := range keywords {
+			if _, isSchema := v[keyword].(map[string]interface{}); !isSchema {
+				delete(v, keyword)
+			}
+		}
+		for _, child := range v {
+			removeKeywords(child, keywords)
+		}
+	case []interface{}:
+		for _, child := range v {
+			removeKeywords(child, keywords)
+		}
+	}
+}
+
+// validateItemSchema checks a json request body against the Server's Item schema, if it has one.
+// A body that is not json passes, so that the decoder reports the syntax error.
+// Returns nil if the body is valid, otherwise the error of the first offending value, as given by schemaError.
+func (s *Server) validateItemSchema(body []byte) error {
+	if s.itemSchema == nil {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil
+	}
+	if err := s.itemSchema.Validate(doc); err != nil {
+		if validationErr, ok := err.(*jsonschema.ValidationError); ok {
+			return schemaError(validationErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// schemaError describes a schema validation failure by its first offending value, ordered by location,
+// e.g. "/quantity: expected integer, but got number", so that the same body always gets the same error.
+// If the value is within a top-level field, the error is a FieldError naming the field.
+func schemaError(err *jsonschema.ValidationError) error {
+	leaves := []*jsonschema.ValidationError{}
+	var collect func(e *jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			leaves = append(leaves, e)
+		}
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	collect(err)
+	sort.SliceStable(leaves, func(i, j int) bool {
+		if leaves[i].InstanceLocation != leaves[j].InstanceLocation {
+			return leaves[i].InstanceLocation < leaves[j].InstanceLocation
+		}
+		return leaves[i].Message < leaves[j].Message
+	})
+
+	leaf := leaves[0]
+	location := leaf.InstanceLocation
+	if location == "" {
+		location = "/"
+	}
+	field := strings.SplitN(strings.TrimPrefix(leaf.InstanceLocation, "/"), "/", 2)[0]
+	if field == "" {
+		return fmt.Errorf("%s: %s", location, leaf.Message)
+	}
+	return models.NewFieldError(field, "%s: %s", location, leaf.Message)
+}
@@ -1,73 +1,176 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"math"
+	"mime"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/lbisceglia/shopify/db"
 	"github.com/lbisceglia/shopify/models"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
+// VALIDATION_WARNINGS_HEADER is the response header that lists the Warnings for a valid Item that was written,
+// one per header value, e.g. "price is 0.00, description is empty".
+const VALIDATION_WARNINGS_HEADER = "X-Validation-Warnings"
+
 // An InventoryServer responds to HTTP requests on the inventory.
 // It supports to the following RESTful actions:
 // - Create a new inventory item;
-// - Update the data on an existing inventory item;
-// - Permanently delete an existing inventory item;
-// - Retrieve all items in inventory; and
-// - Retrieve a single inventory item.
+// - Update the data on an existing inventory item, in full or with a JSON Merge Patch;
+// - Delete one or several existing inventory items;
+// - Retrieve all items in inventory, or those that have been deleted;
+// - Stream the whole inventory as newline-delimited json;
+// - Retrieve a single inventory item, by ID or by SKU, or several by ID;
+// - Retrieve all tags in use on inventory items;
+// - Adjust, increment, or decrement the quantity of an existing inventory item;
+// - Retrieve the quantity history of one or several inventory items;
+// - Reserve and release the stock of an inventory item;
+// - Report on the margin made on inventory items, and on inventory as a whole; and
+// - Import inventory items with pre-set IDs (admin only);
+// - Preview the impact of normalizing SKUs (admin only);
+// - Perform database maintenance (admin only);
+// - Expose operational metrics to Prometheus;
+// - Describe the API as an OpenAPI document; and
+// - Identify the running build.
 type InventoryServer interface {
 	CreateItem(w http.ResponseWriter, r *http.Request)
 	UpdateItem(w http.ResponseWriter, r *http.Request)
+	UpdateItems(w http.ResponseWriter, r *http.Request)
+	PatchItem(w http.ResponseWriter, r *http.Request)
 	DeleteItem(w http.ResponseWriter, r *http.Request)
+	DeleteItems(w http.ResponseWriter, r *http.Request)
 	GetItems(w http.ResponseWriter, r *http.Request)
+	GetDeletedItems(w http.ResponseWriter, r *http.Request)
+	StreamItems(w http.ResponseWriter, r *http.Request)
 	GetItem(w http.ResponseWriter, r *http.Request)
+	GetItemBySKU(w http.ResponseWriter, r *http.Request)
+	GetItemByBarcode(w http.ResponseWriter, r *http.Request)
+	SearchItems(w http.ResponseWriter, r *http.Request)
+	GetItemsByIDs(w http.ResponseWriter, r *http.Request)
+	GetTags(w http.ResponseWriter, r *http.Request)
+	AdjustQuantity(w http.ResponseWriter, r *http.Request)
+	Stocktake(w http.ResponseWriter, r *http.Request)
+	Transfer(w http.ResponseWriter, r *http.Request)
+	Increment(w http.ResponseWriter, r *http.Request)
+	Decrement(w http.ResponseWriter, r *http.Request)
+	GetItemHistory(w http.ResponseWriter, r *http.Request)
+	GetItemHistories(w http.ResponseWriter, r *http.Request)
+	Reserve(w http.ResponseWriter, r *http.Request)
+	Release(w http.ResponseWriter, r *http.Request)
+	GetMarginReport(w http.ResponseWriter, r *http.Request)
+	GetReorderReport(w http.ResponseWriter, r *http.Request)
+	GetItemStats(w http.ResponseWriter, r *http.Request)
+	ImportItems(w http.ResponseWriter, r *http.Request)
+	PreviewSKUNormalization(w http.ResponseWriter, r *http.Request)
+	Analyze(w http.ResponseWriter, r *http.Request)
+	Metrics(w http.ResponseWriter, r *http.Request)
+	OpenAPI(w http.ResponseWriter, r *http.Request)
+	Version(w http.ResponseWriter, r *http.Request)
+	Instrument(next http.Handler) http.Handler
+	Authenticate(next http.Handler) http.Handler
 }
 
 // A Server is an implementation of an Inventory Server.
 type Server struct {
-	db db.DB
+	db         db.DB
+	config     Config
+	metrics    *metrics
+	webhook    *webhook
+	itemSchema *jsonschema.Schema // schema of request Items, or nil if they are not validated against one
 }
 
 // NewServer creates a new instance of an Inventory Server with the specified database.
+// The Server is configured from the environment.
 func NewServer(db db.DB) InventoryServer {
+	config := NewConfig()
+	models.RequireAlphanumericSKU = config.RequireAlphanumericSKU
+	models.UppercaseSKU = config.UppercaseSKU
+	models.UniqueNames = config.UniqueNames
+	models.UniqueBarcodes = config.UniqueBarcodes
+	models.EnforceReservedStock = config.EnforceReservedStock
+	models.StrictQuantity = config.StrictQuantity
+	models.SKUMinLen = config.SKUMinLen
+	models.SKUMaxLen = config.SKUMaxLen
+	models.NameMaxLen = config.NameMaxLen
+	models.DescriptionMaxLen = config.DescriptionMaxLen
+	models.ItemIDFormat = config.IDFormat
+	models.DefaultCurrency = config.DefaultCurrency
+
+	if itemSchemaErr != nil {
+		slog.Warn("cannot compile item schema; request items will not be validated against it", "error", itemSchemaErr)
+	}
 	return &Server{
-		db: db,
+		db:         db,
+		config:     config,
+		metrics:    newMetrics(),
+		webhook:    newWebhook(config.WebhookURL),
+		itemSchema: publishedItemSchema,
 	}
 }
 
 // CreateItem creates an inventory Item according to the request.
 // It ensures the request Item is well-formed in accordance with the API specification.
 //
-// Returns a 201 Created and responds with the relative URL of the newly-created resource
-// (Header: Location) upon success.
+// Clients that do not need the newly-created Item may opt out of the response body with
+// the Prefer header (RFC 7240) "return=minimal".
+//
+// Returns a 201 Created and responds with the newly-created Item, including its server-assigned
+// ID and timestamps, and the relative URL of the newly-created resource (Header: Location) upon success.
 // Returns a 400 Bad Request if the request is malformed.
-// Returns a 409 Conflict if a non-unique SKU is provided.
+// Returns a 409 Conflict if a non-unique SKU, or a non-unique name when names must be unique, is provided.
 func (s *Server) CreateItem(w http.ResponseWriter, r *http.Request) {
 	s.setHeader(w)
 	var item models.Item
 
 	// Decode and validate the request
-	if !s.decodeRequestItem(w, r.Body, &item) || !s.validateItem(w, &item) {
+	if !s.decodeRequestItem(w, r, &item) || !s.validateItem(w, r, &item) {
 		return
 	}
 
 	// Save item to database
-	code, err := s.db.CreateItem(&item)
+	code, err := s.db.CreateItem(r.Context(), &item)
 
 	if err != nil {
 		// Handle database errors
-		writeError(w, code, err)
+		writeError(w, r, code, s.conflictError(r, code, err))
 		return
 	}
+	s.notify(r.Context(), models.EventItemCreated, item.GetID())
 
 	// Respond with URL of newly-created resource
 	relativeURL := fmt.Sprintf("/%s", item.GetID())
 	w.Header().Set("Location", relativeURL)
+
+	switch preference(r, "return") {
+	case "minimal":
+		w.Header().Set("Preference-Applied", "return=minimal")
+		w.WriteHeader(code)
+		return
+	case "representation":
+		w.Header().Set("Preference-Applied", "return=representation")
+	}
+
+	// Respond with newly-created resource
+	item.ComputeDerived()
 	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(models.NewCreatedItem(&item)); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
 }
 
 // UpdateItem updates an inventory Item according to the request.
@@ -75,136 +178,1781 @@ func (s *Server) CreateItem(w http.ResponseWriter, r *http.Request) {
 // It does not perform partial updates; any optional fields will be overwritten with
 // their default values if they are missing from the request.
 //
+// Clients may opt in to upsert semantics by sending the "X-Upsert: true" header.
+// An upsert creates the Item with the ID in the URL endpoint if it does not already exist,
+// instead of responding with a 404 Not Found. Without the header, existing clients see no change.
+//
+// Clients may make the update conditional by giving the version of the Item they last read, as given by GetItem,
+// in the body or as the Item's ETag in the If-Match header, e.g. `If-Match: "3"`, so that an Item that has changed since is not overwritten.
+// `If-Match: *` only updates an Item that exists, so it is never upserted. Without a version, the Item is updated unconditionally.
+//
+// Returns a 201 Created and responds with the relative URL of the newly-created resource
+// (Header: Location) if an upsert created the Item.
 // Returns a 204 No Content on success.
-// Returns a 400 Bad Request if the request is malformed.
-// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
-// Returns a 409 Conflict if a non-unique SKU is provided as part of the update.
+// Returns a 400 Bad Request if the request is malformed, including an upsert to a malformed ID.
+// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint and the request is not an upsert.
+// Returns a 409 Conflict if a non-unique SKU, or a non-unique name when names must be unique, is provided
+// as part of the update, if the quantity would no longer cover the reserved stock and this is enforced,
+// or if the Item is no longer at the version given.
+// Returns a 412 Precondition Failed if the If-Match header is "*" and there is no Item to match.
 func (s *Server) UpdateItem(w http.ResponseWriter, r *http.Request) {
 	s.setHeader(w)
 	var item models.Item
 
 	// Decode and validate the request
-	if !s.decodeRequestItem(w, r.Body, &item) || !s.validateItem(w, &item) {
+	if !s.decodeRequestItem(w, r, &item) || !s.validateItem(w, r, &item) {
 		return
 	}
 
-	// Update item in database
+	if code, err := ifMatchVersion(r, &item); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	id := models.ID(mux.Vars(r)["id"])
+	if !isUpsert(r) || ifMatchAny(r) {
+		// Update item in database
+		change, code, err := s.db.UpdateItem(r.Context(), &id, &item)
+
+		if code == http.StatusNotFound && ifMatchAny(r) {
+			writeError(w, r, http.StatusPreconditionFailed, fmt.Errorf("there is no item with ID %v to match", id))
+			return
+		} else if err != nil {
+			// Handle database errors
+			writeError(w, r, code, s.conflictError(r, code, err))
+			return
+		}
+		s.notifyStock(r.Context(), models.EventItemUpdated, change)
+
+		w.WriteHeader(code)
+		return
+	}
+
+	// Upserts may create the item, so its ID must be well-formed
+	if code, err := (&models.Item{ID: id}).ValidateID(); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Update or create item in database
+	change, code, err := s.db.UpsertItem(r.Context(), &id, &item)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, s.conflictError(r, code, err))
+		return
+	}
+
+	if code == http.StatusCreated {
+		s.notify(r.Context(), models.EventItemCreated, id)
+
+		// Respond with URL of newly-created resource
+		relativeURL := fmt.Sprintf("/%s", id)
+		w.Header().Set("Location", relativeURL)
+	} else {
+		s.notifyStock(r.Context(), models.EventItemUpdated, change)
+	}
+	w.WriteHeader(code)
+}
+
+// UpdateItems updates several inventory Items at once according to the request, each as in UpdateItem, by the ID in its body,
+// e.g. to change the prices of a whole product line without a request per Item.
+// Items that cannot be updated are skipped, unless the atomic query parameter is true,
+// in which case the Items are updated in a single transaction and none are updated if any cannot be.
+//
+// Returns the outcome of each Item's update, in the order they were given, and a 200 OK on success:
+// a 204 No Content if the Item was updated, a 404 Not Found if there was no Item with its ID,
+// or a 409 Conflict if its SKU, name, or barcode was already in use.
+// Returns a 400 Bad Request if the request is malformed, any Item is invalid, or any two Items share a SKU, compared case-insensitively.
+// Returns a 404 Not Found or a 409 Conflict as in UpdateItem if the update is atomic and any Item cannot be updated.
+func (s *Server) UpdateItems(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	atomic, code, err := isAtomic(r)
+	if err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Decode and validate the request
+	var items []models.Item
+	if !s.decodeRequest(w, r, &items) {
+		return
+	}
+	if code, err := models.ValidateBulkUpdate(items); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Update items in database
+	results, code, err := s.db.UpdateItems(r.Context(), items, atomic)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, s.conflictError(r, code, err))
+		return
+	}
+	for i, result := range results {
+		if result.Status == http.StatusNoContent {
+			s.notifyStock(r.Context(), models.EventItemUpdated, result.Change)
+		} else if result.Field != "" {
+			result.Error = s.conflictError(r, result.Status, models.NewFieldError(result.Field, "%s", result.Error)).Error()
+		}
+		results[i] = result
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with the result of each update
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// PatchItem partially updates an inventory Item with a JSON Merge Patch (RFC 7386),
+// sent with the "Content-Type: application/merge-patch+json" header.
+// Fields present in the patch are updated, fields set to null are reset to their defaults,
+// and absent fields are untouched. The patched Item is validated as in UpdateItem.
+// The patch is applied to the version of the Item that was read, so the Item is not updated if it changes in the meantime.
+// Clients may also give the version they expect in the If-Match header, as in UpdateItem.
+//
+// Returns a 204 No Content on success.
+// Returns a 400 Bad Request if the patch is malformed, sets quantity to null, or results in an invalid Item.
+// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
+// Returns a 409 Conflict as in UpdateItem.
+// Returns a 412 Precondition Failed if the If-Match header is "*" and there is no Item to match.
+// Returns a 415 Unsupported Media Type if the request is not a JSON Merge Patch.
+func (s *Server) PatchItem(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	mediaType := strings.TrimSpace(strings.SplitN(r.Header.Get("Content-Type"), ";", 2)[0])
+	if !strings.EqualFold(mediaType, models.MERGE_PATCH_MEDIA_TYPE) {
+		w.Header().Set("Accept-Patch", models.MERGE_PATCH_MEDIA_TYPE)
+		writeError(w, r, http.StatusUnsupportedMediaType, fmt.Errorf("patches must have the %s Content-Type", models.MERGE_PATCH_MEDIA_TYPE))
+		return
+	}
+
+	// Decode the patch
+	var patch json.RawMessage
+	if !s.decodeRequest(w, r, &patch) {
+		return
+	}
+
+	// Get item from the primary database, as a replica may lag behind the Item being patched
 	id := models.ID(mux.Vars(r)["id"])
-	code, err := s.db.UpdateItem(&id, &item)
+	current, code, err := s.db.GetItem(db.WithPrimary(r.Context()), &id)
+	if code == http.StatusNotFound && ifMatchAny(r) {
+		writeError(w, r, http.StatusPreconditionFailed, fmt.Errorf("there is no item with ID %v to match", id))
+		return
+	} else if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+	var expected models.Item
+	if code, err := ifMatchVersion(r, &expected); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+	if code, err := models.CheckVersion(id, current.Version, expected.Version); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Apply the patch, then decode and validate the result
+	merged, code, err := current.MergePatch(patch)
+	if err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+	if err := s.validateItemSchema(merged); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var item models.Item
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&item); err != nil {
+		writeError(w, r, http.StatusBadRequest, decodeError(err))
+		return
+	}
+	if !s.validateItem(w, r, &item) {
+		return
+	}
 
+	// Update item in database
+	change, code, err := s.db.UpdateItem(r.Context(), &id, &item)
 	if err != nil {
 		// Handle database errors
-		writeError(w, code, err)
+		writeError(w, r, code, s.conflictError(r, code, err))
 		return
 	}
+	s.notifyStock(r.Context(), models.EventItemUpdated, change)
 
 	w.WriteHeader(code)
 }
 
-// Delete Item permanently removes an item from inventory.
+// Delete Item removes an item from inventory, keeping a record of it among the deleted Items.
+//
+// Clients may make the deletion conditional by sending the If-Match header with the ETag of the Item,
+// as given by GetItem, so that an Item that has changed since the client read it is not removed.
+// The ETag is given by itemETag, so it matches whichever fields were read and whether or not they were compressed.
+// Without the header, the Item is removed unconditionally.
 //
 // Returns a 204 No Content on success.
 // Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
+// Returns a 412 Precondition Failed if the If-Match header does not match the Item's current ETag,
+// or if there is no Item to match.
 func (s *Server) DeleteItem(w http.ResponseWriter, r *http.Request) {
 	s.setHeader(w)
+	id := models.ID(mux.Vars(r)["id"])
+
+	// Check the precondition against the item in the primary database, as a replica may lag behind it
+	var lastUpdated *time.Time
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		item, code, err := s.db.GetItem(db.WithPrimary(r.Context()), &id)
+		if code == http.StatusNotFound {
+			writeError(w, r, http.StatusPreconditionFailed, fmt.Errorf("there is no item with ID %v to match", id))
+			return
+		} else if err != nil {
+			writeError(w, r, code, err)
+			return
+		}
+		if !etagMatchesStrongly(ifMatch, itemETag(item)) {
+			writeError(w, r, http.StatusPreconditionFailed, fmt.Errorf("item with ID %v has been modified", id))
+			return
+		}
+		lastUpdated = item.LastUpdated
+	}
 
 	// Delete item from database
-	id := models.ID(mux.Vars(r)["id"])
-	code, err := s.db.DeleteItem(&id)
+	code, err := s.db.DeleteItem(r.Context(), &id, lastUpdated)
 
 	if err != nil {
 		// Handle database errors
-		writeError(w, code, err)
+		writeError(w, r, code, err)
 		return
 	}
+	s.notify(r.Context(), models.EventItemDeleted, id)
 
 	w.WriteHeader(code)
 }
 
-// GetItems returns a collection of all Items in inventory.
+// DeleteItems removes several items from inventory at once, as listed by the ids query parameter, e.g. "?ids=a,b,c",
+// keeping a record of them among the deleted Items. Either every listed item that exists is removed or none are.
 //
-// Returns all Items and a 200 OK on success.
-func (s *Server) GetItems(w http.ResponseWriter, r *http.Request) {
-	// TODO: paginate
+// Returns whether each item was deleted or not found, by ID, and a 200 OK on success.
+// Returns a 400 Bad Request if no ids or too many ids are listed.
+func (s *Server) DeleteItems(w http.ResponseWriter, r *http.Request) {
 	s.setHeader(w)
 
-	// Get items from databse
-	items, code, err := s.db.GetItems()
+	// Parse the ids
+	ids, code, err := models.ParseIDs(r.URL.Query().Get("ids"))
+	if err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Delete items from database
+	results, code, err := s.db.DeleteItems(r.Context(), ids)
 
 	if err != nil {
 		// Handle database errors
-		writeError(w, code, err)
+		writeError(w, r, code, err)
 		return
 	}
+	for _, id := range ids {
+		if results[id] == http.StatusNoContent {
+			s.notify(r.Context(), models.EventItemDeleted, id)
+		}
+	}
 
 	w.WriteHeader(code)
 
-	// Respond with items
+	// Respond with the result of each deletion
+	if err := json.NewEncoder(w).Encode(models.NewDeleteResults(results)); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// GetItems returns a collection of all Items in inventory that match the request's query parameters.
+// Supported query parameters are:
+// - min_value: only return Items whose stock value (price * quantity) in the default currency is at least min_value.
+// - min_price, max_price: only return Items whose price in the default currency is within [min_price, max_price].
+//   Unpriced Items are excluded by min_price but not by max_price alone.
+// - in_stock: if true, only return Items with stock available to sell (quantity - reserved > 0); if false, only those without.
+// - tag: only return Items with the tag. May be repeated to require several tags.
+// - added_after, added_before: only return Items added within [added_after, added_before), as RFC3339 timestamps.
+// - updated_after, updated_before: only return Items last updated within [updated_after, updated_before).
+// - include_deleted: if true, also return deleted Items, along with when each was deleted as deleted_at.
+// - deleted_after, deleted_before: only return deleted Items deleted within [deleted_after, deleted_before).
+//   Items in inventory never match them, so they are meant for use with include_deleted or GetDeletedItems.
+// - limit, offset: only return a page of at most limit Items, after skipping the first offset.
+// - after: only return a page of at most limit Items that come after the cursor, ordered by date added and then by ID.
+//   An empty cursor starts from the first Item. If there may be more Items, the cursor of the next page
+//   is returned in the X-Next-Cursor header. Cannot be combined with offset or the envelope.
+//
+// By default, the response is a bare json array of Items. Clients that send the
+// "Accept: application/vnd.inventory.v2+json" header or the envelope=true query parameter instead get
+// an envelope holding the page of Items and a description of the page, with links to the neighbouring pages.
+// The envelope is always paginated, DEFAULT_PAGE_LIMIT Items at a time unless limit is given.
+//
+// The Items are streamed to the response as they are read from the database, so that memory use
+// does not grow with the size of the inventory.
+// The response carries a weak ETag computed from the version of the matching Items;
+// a request whose If-None-Match header matches it gets a 304 Not Modified.
+//
+// Returns the matching Items and a 200 OK on success.
+// Returns a 304 Not Modified if the client's copy of the Items is current.
+// Returns a 400 Bad Request if a query parameter is malformed.
+func (s *Server) GetItems(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	w.Header().Add("Vary", "Accept")
+
+	// Parse the filter
+	filter, code, err := parseFilter(r)
+	if err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+	s.listItems(w, r, filter)
+}
+
+// GetDeletedItems returns a collection of the deleted Items that match the request's query parameters,
+// along with when each was deleted as deleted_at. Deleted Items no longer have tags or images.
+// It supports the same query parameters and responses as GetItems, e.g. limit and offset,
+// or deleted_after and deleted_before to find the Items deleted last week; include_deleted has no effect.
+//
+// Returns the matching deleted Items and a 200 OK on success.
+// Returns a 304 Not Modified if the client's copy of the Items is current.
+// Returns a 400 Bad Request if a query parameter is malformed.
+func (s *Server) GetDeletedItems(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	w.Header().Add("Vary", "Accept")
+
+	// Parse the filter
+	filter, code, err := parseFilter(r)
+	if err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+	filter.IncludeDeleted = false
+	filter.OnlyDeleted = true
+	s.listItems(w, r, filter)
+}
+
+// StreamItems responds with every Item in inventory as newline-delimited json (application/x-ndjson), one Item per line,
+// ordered by date added and then by ID, e.g. for bulk exports to a data warehouse.
+// Items are written as they are read from the database and flushed periodically, so that the catalog is never held in memory
+// and no pagination is needed.
+// If reading the Items fails after the response has started, the response is cut short and the error is logged.
+//
+// Returns the Items and a 200 OK on success.
+func (s *Server) StreamItems(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", NDJSON_MEDIA_TYPE)
+
+	// Stream items from database
+	stream := newNDJSONStream(w)
+	code, err := s.db.StreamItems(r.Context(), &models.Filter{}, stream.Write)
+
+	if err != nil {
+		if !stream.Started() {
+			// Handle database errors
+			writeError(w, r, code, err)
+			return
+		}
+		// The response is already underway, so it can only be cut short
+		requestLog(r.Context()).Error("cannot stream items", "error", err)
+		return
+	}
+
+	if err := stream.Close(); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// listItems responds with the Items that match the filter, as described by GetItems.
+func (s *Server) listItems(w http.ResponseWriter, r *http.Request, filter models.Filter) {
+	envelope, code, err := wantsEnvelope(r)
+	if err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+	if envelope && filter.Limit == 0 {
+		filter.Limit = models.DEFAULT_PAGE_LIMIT
+	}
+	query := r.URL.Query()
+	if _, ok := query["after"]; ok {
+		if query.Get("offset") != "" || envelope {
+			writeError(w, r, http.StatusBadRequest, errors.New("after cannot be combined with offset or envelope"))
+			return
+		}
+		s.getItemsAfter(w, r, &filter, query.Get("after"))
+		return
+	}
+
+	// Check whether the client's copy is current
+	version, code, err := s.db.GetItemsVersion(r.Context(), &filter)
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+	key := version + r.URL.Path + "?" + r.URL.RawQuery
+	if envelope {
+		key += "+envelope"
+	}
+	etag := fmt.Sprintf(`W/"%x"`, sha256.Sum256([]byte(key)))
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	// Stream items from database
+	stream := newItemStream(w)
+	if envelope {
+		total, code, err := s.db.CountItems(r.Context(), &filter)
+		if err != nil {
+			// Handle database errors
+			writeError(w, r, code, err)
+			return
+		}
+		page := models.NewPage(filter.Limit, filter.Offset, total, r.URL.Path, r.URL.Query())
+		if err := stream.Envelope(page); err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if accepts(r, ENVELOPE_MEDIA_TYPE) {
+			w.Header().Set("Content-Type", ENVELOPE_MEDIA_TYPE)
+		}
+	}
+	code, err = s.eachItem(r.Context(), &filter, stream.Write)
+
+	if err != nil {
+		if !stream.Started() {
+			// Handle database errors
+			writeError(w, r, code, err)
+			return
+		}
+		// The response is already underway, so it can only be cut short
+		requestLog(r.Context()).Error("cannot stream items", "error", err)
+		return
+	}
+
+	if err := stream.Close(); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// eachItem calls fn on each Item that matches the filter, or on the filter's page of them.
+// Items in inventory are streamed from the database one at a time, while deleted Items are read by GetDeletedItems,
+// a page at a time if the request is paginated.
+// Returns a 200 OK and nil if successful, otherwise the error of the database or fn.
+func (s *Server) eachItem(ctx context.Context, filter *models.Filter, fn func(item *models.Item) error) (int, error) {
+	if !filter.OnlyDeleted {
+		return s.db.StreamItems(ctx, filter, fn)
+	}
+	items, code, err := s.db.GetDeletedItems(ctx, filter)
+	if err != nil {
+		return code, err
+	}
+	for i := range items {
+		if err := fn(&items[i]); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// getItemsAfter responds with the page of Items that match the filter and come after the encoded cursor.
+// An empty cursor starts from the first Item. The page holds at most the filter's limit of Items,
+// or DEFAULT_PAGE_LIMIT if none is given. If there are more Items, the cursor of the next page is
+// returned in the X-Next-Cursor header.
+func (s *Server) getItemsAfter(w http.ResponseWriter, r *http.Request, filter *models.Filter, after string) {
+	cursor := models.Cursor{}
+	if after != "" {
+		var code int
+		var err error
+		if cursor, code, err = models.ParseCursor(after); err != nil {
+			writeError(w, r, code, err)
+			return
+		}
+	}
+	limit := filter.Limit
+	if limit == 0 {
+		limit = models.DEFAULT_PAGE_LIMIT
+	}
+
+	// Fetch one extra Item to learn whether there is a next page
+	items, code, err := s.db.GetItemsAfter(r.Context(), filter, cursor, limit+1)
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+	if len(items) > limit {
+		items = items[:limit]
+		w.Header().Set("X-Next-Cursor", models.CursorOf(&items[limit-1]).String())
+	}
+	for i := range items {
+		items[i].ComputeDerived()
+	}
+
+	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(items); err != nil {
-		log.Println(err)
+		requestLog(r.Context()).Error("cannot write response", "error", err)
 	}
 }
 
 // GetItem returns a single inventory Item
+// It supports the following optional query parameter:
+// - fields: a comma-separated list of the Item fields to respond with, e.g. "id,name,price".
+//
+// The response carries the Item's ETag, as given by itemETag, whichever fields are requested;
+// a request whose If-None-Match header matches it gets a 304 Not Modified.
 //
 // Returns the Item and a 200 OK on success.
+// Returns a 304 Not Modified if the client's copy of the Item is current.
+// Returns a 400 Bad Request if a requested field is unknown.
 // Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
 func (s *Server) GetItem(w http.ResponseWriter, r *http.Request) {
 	s.setHeader(w)
 
+	// Parse the requested fields
+	fields, code, err := models.ParseFields(r.URL.Query().Get("fields"))
+	if err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
 	// Get item from database
 	id := models.ID(mux.Vars(r)["id"])
-	item, code, err := s.db.GetItem(&id)
+	var item models.Item
+	if fields == nil {
+		item, code, err = s.db.GetItem(r.Context(), &id)
+	} else {
+		// The version is always read, as the ETag is computed from it
+		item, code, err = s.db.GetItemFields(r.Context(), &id, append(fields, "version"))
+	}
 
 	if err != nil {
 		// Handle database errors
-		writeError(w, code, err)
+		writeError(w, r, code, err)
 		return
 	}
 
-	w.WriteHeader(code)
+	item.ComputeDerived()
 
-	// Respond with items
-	if err := json.NewEncoder(w).Encode(item); err != nil {
-		log.Println(err)
+	var body interface{} = item
+	if fields != nil {
+		if body, err = item.Project(fields); err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
 	}
+
+	// Respond with item
+	writeCacheable(w, r, code, body, itemETag(item))
 }
 
-/*
-  Helper Methods
-*/
+// GetItemBySKU returns the single inventory Item with the SKU in the URL endpoint, e.g. as read from a barcode label.
+// The SKU is uppercased before the lookup if SKUs are stored in uppercase.
+//
+// The response carries the Item's ETag, as in GetItem;
+// a request whose If-None-Match header matches it gets a 304 Not Modified.
+//
+// Returns the Item and a 200 OK on success.
+// Returns a 304 Not Modified if the client's copy of the Item is current.
+// Returns a 404 Not Found if there is no Item with the SKU.
+func (s *Server) GetItemBySKU(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
 
-// setHeader sets the header's content type to application/json.
-func (s *Server) setHeader(w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json")
+	// Get item from database
+	sku := models.SKU(mux.Vars(r)["sku"])
+	if models.UppercaseSKU {
+		sku = models.NormalizeSKU(sku)
+	}
+	item, code, err := s.db.GetItemBySKU(r.Context(), &sku)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	item.ComputeDerived()
+
+	// Respond with item
+	writeCacheable(w, r, code, item, itemETag(item))
 }
 
-// writeError writes error states to the response.
-// It assumes the error is not nil and will panic if passed a nil error.
-func writeError(w http.ResponseWriter, code int, err error) {
-	msg, _ := json.Marshal(err.Error())
-	w.WriteHeader(code)
-	w.Write(msg)
+// GetItemByBarcode returns the single inventory Item with the EAN-13 barcode in the URL endpoint, e.g. as read by a scanner.
+// If several Items share the barcode, the one added first is returned.
+//
+// The response carries the Item's ETag, as in GetItem;
+// a request whose If-None-Match header matches it gets a 304 Not Modified.
+//
+// Returns the Item and a 200 OK on success.
+// Returns a 304 Not Modified if the client's copy of the Item is current.
+// Returns a 400 Bad Request if the barcode is not a valid EAN-13.
+// Returns a 404 Not Found if there is no Item with the barcode.
+func (s *Server) GetItemByBarcode(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	// Validate barcode
+	barcode := mux.Vars(r)["code"]
+	if code, err := models.ValidateEAN13(barcode); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Get item from database
+	item, code, err := s.db.GetItemByBarcode(r.Context(), barcode)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	item.ComputeDerived()
+
+	// Respond with item
+	writeCacheable(w, r, code, item, itemETag(item))
 }
 
-// decodeRequestItem decodes the json Item embedded in a Request and validates it for type errors.
-// Returns true if decoded successfully, false otherwise.
-func (s *Server) decodeRequestItem(w http.ResponseWriter, body io.ReadCloser, item *models.Item) bool {
-	if err := json.NewDecoder(body).Decode(&item); err != nil {
-		// Malformed request
-		writeError(w, http.StatusBadRequest, err)
-		return false
+// SearchItems returns the inventory Items whose name or description match the full-text search query q,
+// most relevant first, e.g. /api/items/search?q=wireless+headphones.
+// At most limit Items are returned, DEFAULT_PAGE_LIMIT if none is given.
+//
+// Returns the matching Items and a 200 OK on success.
+// Returns a 400 Bad Request if the query is missing or limit is malformed.
+func (s *Server) SearchItems(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	// Validate the query
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		writeError(w, r, http.StatusBadRequest, errors.New("q is required"))
+		return
+	}
+	limit := models.DEFAULT_PAGE_LIMIT
+	if l := query.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > models.MAX_PAGE_LIMIT {
+			writeError(w, r, http.StatusBadRequest, fmt.Errorf("limit must be an integer between 1 and %d", models.MAX_PAGE_LIMIT))
+			return
+		}
+		limit = n
+	}
+
+	// Search the database
+	items, code, err := s.db.FullTextSearch(r.Context(), q, limit)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	for i := range items {
+		items[i].ComputeDerived()
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with items
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
 	}
-	return true
 }
 
-// validateItem validates an Item embedded in a Request to ensure it adheres to API specification.
-// Returns true if the Item is valid, false otherwise.
-func (s *Server) validateItem(w http.ResponseWriter, item *models.Item) bool {
-	if code, err := item.ValidateItem(); err != nil {
+// GetItemsByIDs returns several inventory Items at once, by ID.
+// IDs without an Item are not an error; they are listed as missing in the response.
+//
+// Returns the Items found, in the order they were requested, the missing IDs, and a 200 OK on success.
+// Returns a 400 Bad Request if the request is malformed or requests too many Items.
+func (s *Server) GetItemsByIDs(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	var batch models.ItemBatch
+
+	// Decode and validate the request
+	if !s.decodeRequest(w, r, &batch) {
+		return
+	}
+	if code, err := batch.ValidateItemBatch(); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Get items from database
+	items, code, err := s.db.GetItemsByIDs(r.Context(), batch.IDs)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	for i := range items {
+		items[i].ComputeDerived()
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with items
+	if err := json.NewEncoder(w).Encode(models.NewItemBatchResult(batch.IDs, items)); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// GetTags returns every distinct tag in use on inventory Items and the number of Items that have it.
+//
+// Returns the tags and a 200 OK on success.
+func (s *Server) GetTags(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	// Get tags from database
+	tags, code, err := s.db.GetTags(r.Context())
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with tags
+	if err := json.NewEncoder(w).Encode(tags); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// AdjustQuantity adds the requested amount to the quantity of an inventory Item.
+// A negative amount removes stock. Every adjustment is recorded in the Item's history.
+//
+// Returns a 204 No Content on success.
+// Returns a 400 Bad Request if the request is malformed.
+// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
+// Returns a 409 Conflict if the adjustment would make the quantity negative,
+// or if the quantity would no longer cover the reserved stock and this is enforced.
+func (s *Server) AdjustQuantity(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	var adj models.Adjustment
+
+	// Decode and validate the request
+	if !s.decodeRequest(w, r, &adj) {
+		return
+	}
+	if code, err := adj.ValidateAdjustment(); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Adjust item in database
+	id := models.ID(mux.Vars(r)["id"])
+	change, code, err := s.db.AdjustQuantity(r.Context(), &id, *adj.Amount)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+	s.notifyStock(r.Context(), models.EventItemAdjusted, change)
+
+	w.WriteHeader(code)
+}
+
+// Transfer moves stock from one inventory Item to another in a single transaction, e.g. from a part
+// to the kit assembled from it, so that stock is never removed from one without being added to the other.
+// Both changes are recorded in the Items' histories.
+//
+// Returns a 204 No Content on success.
+// Returns a 400 Bad Request if the request is malformed.
+// Returns a 404 Not Found if either Item does not exist.
+// Returns a 409 Conflict if the source Item has too little stock,
+// or if its quantity would no longer cover its reserved stock and this is enforced.
+func (s *Server) Transfer(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	var transfer models.Transfer
+
+	// Decode and validate the request
+	if !s.decodeRequest(w, r, &transfer) {
+		return
+	}
+	if code, err := transfer.ValidateTransfer(); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Transfer stock in database
+	changes, code, err := s.db.Transfer(r.Context(), &transfer.From, &transfer.To, *transfer.Amount)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+	for _, change := range changes {
+		s.notifyStock(r.Context(), models.EventItemAdjusted, change)
+	}
+
+	w.WriteHeader(code)
+}
+
+// Stocktake reconciles inventory with a physical count, setting the quantity of each counted Item, by SKU,
+// to its counted quantity in a single transaction. Every change is recorded in the Item's history.
+// SKUs without an Item are reported as missing without failing the stocktake,
+// unless the atomic query parameter is true, in which case none of the counts are applied.
+//
+// Returns the change made to each counted Item, the missing SKUs, and a 200 OK on success.
+// Returns a 400 Bad Request if the request is malformed.
+// Returns a 404 Not Found if the stocktake is atomic and any SKU has no Item.
+// Returns a 409 Conflict if any quantity would no longer cover the reserved stock and this is enforced.
+func (s *Server) Stocktake(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	atomic, code, err := isAtomic(r)
+	if err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Decode and validate the request
+	var counts []models.Count
+	if !s.decodeRequest(w, r, &counts) {
+		return
+	}
+	if code, err := models.ValidateStocktake(counts); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Apply the counts in database
+	result, code, err := s.db.Stocktake(r.Context(), counts, atomic)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+	for _, change := range result.Counted {
+		if change.Delta != 0 {
+			s.notifyStock(r.Context(), models.EventItemAdjusted, models.QuantityChange{ID: change.ID, OldQuantity: change.OldQuantity, NewQuantity: change.NewQuantity})
+		}
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with the result of the stocktake
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// Increment adds stock to an inventory Item without a request body, e.g. for barcode scanners.
+// The optional "by" query parameter is the number of units to add, 1 by default.
+// It is recorded in the Item's history as an adjustment.
+//
+// Returns a 204 No Content on success.
+// Returns a 400 Bad Request if "by" is not a positive integer.
+// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
+func (s *Server) Increment(w http.ResponseWriter, r *http.Request) {
+	s.step(w, r, 1)
+}
+
+// Decrement removes stock from an inventory Item without a request body, e.g. for barcode scanners.
+// The optional "by" query parameter is the number of units to remove, 1 by default.
+// It is recorded in the Item's history as an adjustment.
+//
+// Returns a 204 No Content on success.
+// Returns a 400 Bad Request if "by" is not a positive integer.
+// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
+// Returns a 409 Conflict if the decrement would make the quantity negative,
+// or if the quantity would no longer cover the reserved stock and this is enforced.
+func (s *Server) Decrement(w http.ResponseWriter, r *http.Request) {
+	s.step(w, r, -1)
+}
+
+// GetItemHistory returns every recorded change to an inventory Item's quantity, oldest first.
+//
+// Returns the history and a 200 OK on success.
+// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
+func (s *Server) GetItemHistory(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	// Get history from database
+	id := models.ID(mux.Vars(r)["id"])
+	history, code, err := s.db.GetItemHistory(r.Context(), &id)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with history
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// GetItemHistories returns every recorded change to the quantity of each of the requested inventory Items.
+// Items without any history are omitted.
+//
+// Returns the histories by Item ID and a 200 OK on success.
+// Returns a 400 Bad Request if the request is malformed or requests too many Items.
+func (s *Server) GetItemHistories(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	var batch models.HistoryBatch
+
+	// Decode and validate the request
+	if !s.decodeRequest(w, r, &batch) {
+		return
+	}
+	if code, err := batch.ValidateHistoryBatch(); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Get histories from database
+	histories, code, err := s.db.GetItemHistories(r.Context(), batch.IDs)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with histories
+	if err := json.NewEncoder(w).Encode(histories); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// Reserve holds the requested amount of an inventory Item's stock, e.g. during checkout,
+// without removing it from inventory. Reserved stock is no longer available.
+//
+// Returns a 204 No Content on success.
+// Returns a 400 Bad Request if the request is malformed.
+// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
+// Returns a 409 Conflict if less than the requested amount of stock is available.
+func (s *Server) Reserve(w http.ResponseWriter, r *http.Request) {
+	s.changeReservation(w, r, s.db.Reserve)
+}
+
+// Release returns the requested amount of an inventory Item's reserved stock to available stock.
+//
+// Returns a 204 No Content on success.
+// Returns a 400 Bad Request if the request is malformed.
+// Returns a 404 Not Found if there is no resource corresponding to the URL endpoint.
+// Returns a 409 Conflict if less than the requested amount of stock is reserved.
+func (s *Server) Release(w http.ResponseWriter, r *http.Request) {
+	s.changeReservation(w, r, s.db.Release)
+}
+
+// GetMarginReport returns the margin (price - cost) made on each Item in inventory,
+// as well as the total margin across all Items.
+// Items whose cost exceeds their price are flagged as having a negative margin.
+//
+// Returns the margin report and a 200 OK on success.
+func (s *Server) GetMarginReport(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	// Get items from database
+	items, code, err := s.db.GetItems(r.Context(), &models.Filter{})
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with margin report
+	if err := json.NewEncoder(w).Encode(models.NewMarginReport(items)); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// GetReorderReport returns the inventory Items whose quantity has fallen to their reorder point,
+// along with the quantity suggested for restocking each, e.g. /api/items/reorder?supplier=Acme.
+// The report may be restricted to the Items of a single supplier.
+//
+// Returns the Items to reorder and a 200 OK on success.
+func (s *Server) GetReorderReport(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	// Get items from database
+	filter := models.Filter{NeedsReorder: true, Supplier: strings.TrimSpace(r.URL.Query().Get("supplier"))}
+	items, code, err := s.db.GetItems(r.Context(), &filter)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with reorder report
+	if err := json.NewEncoder(w).Encode(models.NewReorderReport(items)); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// GetItemStats returns aggregate statistics over the inventory in a single call, e.g. for a dashboard:
+// the number of Items, the total units in stock, the total value of the stock, and the number of Items out of stock.
+// The statistics are computed by the database, without reading every Item.
+//
+// Returns the statistics and a 200 OK on success.
+func (s *Server) GetItemStats(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+
+	// Get statistics from database
+	stats, code, err := s.db.Stats(r.Context())
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with statistics
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// ImportItems writes a batch of inventory Items with pre-set IDs according to the request.
+// It is intended for migrating inventory between environments and is restricted to admins.
+// Every Item must have a well-formed ID and be well-formed in accordance with the API specification.
+// The batch is imported atomically; if any Item is rejected, none are imported.
+// Items that share a SKU, compared case-insensitively, are rejected before any is written.
+//
+// Returns a 201 Created on success.
+// Returns a 400 Bad Request if the request is malformed.
+// Returns a 401 Unauthorized if no admin API key is provided.
+// Returns a 403 Forbidden if the admin API key is wrong or admin endpoints are disabled.
+// Returns a 409 Conflict if any ID or SKU is not unique.
+func (s *Server) ImportItems(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	// Decode and validate the request
+	var items []models.Item
+	if !s.decodeRequest(w, r, &items) {
+		return
+	}
+	for i := range items {
+		if code, err := items[i].ValidateID(); err != nil {
+			writeError(w, r, code, fmt.Errorf("item %d: %v", i, err))
+			return
+		}
+		if code, err := items[i].ValidateItem(); err != nil {
+			writeError(w, r, code, fmt.Errorf("item %d: %v", i, err))
+			return
+		}
+	}
+	if code, err := models.ValidateBatchSKUs(items); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Save items to database
+	code, err := s.db.ImportItems(r.Context(), items)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+}
+
+// PreviewSKUNormalization reports how many SKUs would change if every SKU were normalized
+// (trimmed and uppercased), and which inventory Items would then collide on the same SKU.
+// It is a read-only analysis, intended to be run before enabling SKU normalization, and is restricted to admins.
+//
+// Returns the report and a 200 OK on success.
+// Returns a 401 Unauthorized if no admin API key is provided.
+// Returns a 403 Forbidden if the admin API key is wrong or admin endpoints are disabled.
+func (s *Server) PreviewSKUNormalization(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+
+	// Get items from database
+	items, code, err := s.db.GetItems(r.Context(), &models.Filter{})
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+
+	// Respond with normalization report
+	if err := json.NewEncoder(w).Encode(models.NewSKUNormalizationReport(items)); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// Analyze refreshes the database's query planner statistics, e.g. after a large import.
+// If the vacuum query parameter is true, the database also reclaims storage held by deleted rows.
+// It is restricted to admins and must be enabled in the configuration.
+//
+// Returns a 204 No Content on success.
+// Returns a 400 Bad Request if the vacuum query parameter is malformed.
+// Returns a 401 Unauthorized if no admin API key is provided.
+// Returns a 403 Forbidden if the admin API key is wrong or maintenance is disabled.
+// Returns a 500 Internal Server Error if the maintenance fails.
+func (s *Server) Analyze(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	if !s.authorizeAdmin(w, r) {
+		return
+	}
+	if !s.config.EnableMaintenance {
+		writeError(w, r, http.StatusForbidden, errors.New("maintenance endpoints are disabled"))
+		return
+	}
+
+	vacuum := false
+	if v := r.URL.Query().Get("vacuum"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, errors.New("vacuum must be true or false"))
+			return
+		}
+		vacuum = b
+	}
+
+	// Perform maintenance on database
+	code, err := s.db.Analyze(r.Context(), vacuum)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+}
+
+/*
+  Helper Methods
+*/
+
+// setHeader sets the header's content type to application/json.
+func (s *Server) setHeader(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+}
+
+// authorizeAdmin checks that the request carries the admin API key as a bearer token.
+// Returns true if the request is authorized, false otherwise.
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if s.config.AdminAPIKey == "" {
+		writeError(w, r, http.StatusForbidden, errors.New("admin endpoints are disabled"))
+		return false
+	}
+
+	key, ok := bearerToken(r)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, errors.New("missing admin API key"))
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(key), []byte(s.config.AdminAPIKey)) != 1 {
+		writeError(w, r, http.StatusForbidden, errors.New("invalid admin API key"))
+		return false
+	}
+	return true
+}
+
+// writeCacheable writes a json body to the response along with the given ETag.
+// If the request's If-None-Match header matches the ETag, a 304 Not Modified is written without the body instead.
+func writeCacheable(w http.ResponseWriter, r *http.Request, code int, v interface{}, etag string) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	body = append(body, '\n')
+
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(code)
+	if _, err := w.Write(body); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
+
+// itemETag returns the ETag of an Item: its version, quoted as an entity tag, e.g. `"3"`.
+// As the version changes with every change to the Item, the ETag is not computed from the body it is sent with,
+// so it is the same whichever of the Item's fields are sent and whether or not they are compressed.
+func itemETag(item models.Item) string {
+	return fmt.Sprintf(`"%d"`, item.Version)
+}
+
+// parseItemETag returns the version of an Item's ETag, as given by itemETag, and true,
+// or 0 and false if the tag is not the ETag of an Item.
+func parseItemETag(tag string) (int, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// etagMatchesStrongly returns true if the If-Match header lists the ETag or is "*", false otherwise.
+// ETags are compared strongly, as is required for If-Match, so weak ETags never match.
+func etagMatchesStrongly(ifMatch, etag string) bool {
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || (tag == etag && !strings.HasPrefix(tag, "W/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatches returns true if the If-None-Match header lists the ETag or is "*", false otherwise.
+// ETags are compared weakly, as is required for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeError writes error states to the response.
+// The error is written as a json string, or as plain text if the request's Accept header prefers text/plain.
+// Its Content-Type is set here, before the status code, so that it is correct whether or not the handler
+// has called setHeader; the handler must not have written the status code itself.
+// Any ETag or validation warnings already set for the successful response are removed, as they do not describe the error.
+// If the error is caused by a single field, the field is named in the X-Error-Field header.
+// It assumes the error is not nil and will panic if passed a nil error.
+func writeError(w http.ResponseWriter, r *http.Request, code int, err error) {
+	var fieldErr *models.FieldError
+	if errors.As(err, &fieldErr) {
+		w.Header().Set("X-Error-Field", fieldErr.Field)
+	}
+	w.Header().Del("ETag")
+	w.Header().Del(VALIDATION_WARNINGS_HEADER)
+	w.Header().Add("Vary", "Accept")
+
+	if prefersPlainText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(code)
+		io.WriteString(w, err.Error())
+		return
+	}
+	msg, _ := json.Marshal(err.Error())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(msg)
+}
+
+// conflictError returns the error to write to the response for a database error with the given status code.
+// Unless the Server is configured with VerboseErrors, a 409 Conflict caused by a field already in use by another Item,
+// e.g. its SKU, is logged in full and replaced by a generic error naming only the field,
+// so that clients cannot probe which values are in use. All other errors, including version conflicts, are returned unchanged.
+func (s *Server) conflictError(r *http.Request, code int, err error) error {
+	var fieldErr *models.FieldError
+	if s.config.VerboseErrors || code != http.StatusConflict || !errors.As(err, &fieldErr) || fieldErr.Field == "version" {
+		return err
+	}
+	requestLog(r.Context()).Info("hiding conflict from client", "error", err)
+
+	field := fieldErr.Field
+	switch field {
+	case "sku", "id":
+		field = strings.ToUpper(field)
+	}
+	return models.NewFieldError(fieldErr.Field, "%s already in use", field)
+}
+
+// prefersPlainText returns true if the request's Accept headers rank text/plain above application/json,
+// false otherwise. JSON is preferred on a tie, e.g. if there is no Accept header.
+func prefersPlainText(r *http.Request) bool {
+	return acceptQuality(r, "text/plain") > acceptQuality(r, "application/json")
+}
+
+// acceptQuality returns the quality that the request's Accept headers give a media type, e.g. "text/plain",
+// taken from the most specific media range that matches it: text/plain over text/* over */*.
+// Returns 1 if there is no Accept header, as any media type is acceptable, or 0 if no media range matches.
+func acceptQuality(r *http.Request, mediaType string) float64 {
+	headers := r.Header.Values("Accept")
+	if len(headers) == 0 {
+		return 1
+	}
+
+	kind := strings.SplitN(mediaType, "/", 2)[0]
+	quality, specificity := 0.0, -1
+	for _, header := range headers {
+		for _, mediaRange := range strings.Split(header, ",") {
+			parts := strings.Split(mediaRange, ";")
+			s := -1
+			switch strings.ToLower(strings.TrimSpace(parts[0])) {
+			case mediaType:
+				s = 2
+			case kind + "/*":
+				s = 1
+			case "*/*":
+				s = 0
+			}
+			if s <= specificity {
+				continue
+			}
+			q := 1.0
+			for _, param := range parts[1:] {
+				param = strings.TrimSpace(param)
+				if strings.HasPrefix(param, "q=") {
+					if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+						q = v
+					}
+				}
+			}
+			quality, specificity = q, s
+		}
+	}
+	return quality
+}
+
+// parseFilter parses the query parameters of a request into a Filter.
+// Returns the Filter, 0, and nil if successful.
+// Returns a 400 Bad Request if a query parameter is malformed.
+func parseFilter(r *http.Request) (models.Filter, int, error) {
+	filter := models.Filter{}
+	query := r.URL.Query()
+
+	if v := query.Get("min_value"); v != "" {
+		minValue, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(minValue) {
+			return models.Filter{}, http.StatusBadRequest, fmt.Errorf("min_value must be a number")
+		}
+		filter.MinValue = &minValue
+	}
+
+	prices := []struct {
+		param string
+		bound **float64
+	}{
+		{"min_price", &filter.MinPrice},
+		{"max_price", &filter.MaxPrice},
+	}
+	for _, p := range prices {
+		if v := query.Get(p.param); v != "" {
+			price, err := strconv.ParseFloat(v, 64)
+			if err != nil || math.IsNaN(price) || math.IsInf(price, 0) || price < 0 {
+				return models.Filter{}, http.StatusBadRequest, fmt.Errorf("%s must be a non-negative number", p.param)
+			}
+			*p.bound = &price
+		}
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return models.Filter{}, http.StatusBadRequest, errors.New("min_price cannot be greater than max_price")
+	}
+
+	if v := query.Get("in_stock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
+		if err != nil {
+			return models.Filter{}, http.StatusBadRequest, errors.New("in_stock must be true or false")
+		}
+		filter.InStock = &inStock
+	}
+
+	if v := query.Get("include_deleted"); v != "" {
+		includeDeleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return models.Filter{}, http.StatusBadRequest, errors.New("include_deleted must be true or false")
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	for _, tag := range query["tag"] {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	filter.Supplier = strings.TrimSpace(query.Get("supplier"))
+
+	bounds := []struct {
+		param string
+		bound **time.Time
+	}{
+		{"added_after", &filter.AddedAfter},
+		{"added_before", &filter.AddedBefore},
+		{"updated_after", &filter.UpdatedAfter},
+		{"updated_before", &filter.UpdatedBefore},
+		{"deleted_after", &filter.DeletedAfter},
+		{"deleted_before", &filter.DeletedBefore},
+	}
+	for _, b := range bounds {
+		if v := query.Get(b.param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return models.Filter{}, http.StatusBadRequest, fmt.Errorf("%s must be an RFC3339 timestamp, e.g. 2022-01-10T18:38:38Z", b.param)
+			}
+			*b.bound = &t
+		}
+	}
+
+	limit, offset := query.Get("limit"), query.Get("offset")
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > models.MAX_PAGE_LIMIT {
+			return models.Filter{}, http.StatusBadRequest, fmt.Errorf("limit must be an integer between 1 and %d", models.MAX_PAGE_LIMIT)
+		}
+		filter.Limit = n
+	}
+	if offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return models.Filter{}, http.StatusBadRequest, errors.New("offset must be a non-negative integer")
+		}
+		filter.Offset = n
+		if filter.Limit == 0 {
+			filter.Limit = models.DEFAULT_PAGE_LIMIT
+		}
+	}
+
+	return filter, 0, nil
+}
+
+// wantsEnvelope returns true if the client asked for a paginated envelope rather than a bare json array,
+// with either the "Accept: application/vnd.inventory.v2+json" header or the envelope=true query parameter.
+// Returns a 400 Bad Request if the envelope query parameter is malformed.
+func wantsEnvelope(r *http.Request) (bool, int, error) {
+	if v := r.URL.Query().Get("envelope"); v != "" {
+		envelope, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, http.StatusBadRequest, errors.New("envelope must be true or false")
+		}
+		if envelope {
+			return true, 0, nil
+		}
+	}
+	return accepts(r, ENVELOPE_MEDIA_TYPE), 0, nil
+}
+
+// accepts returns true if the request's Accept headers list the media type, ignoring any parameters.
+func accepts(r *http.Request, mediaType string) bool {
+	for _, header := range r.Header.Values("Accept") {
+		for _, accepted := range strings.Split(header, ",") {
+			accepted = strings.TrimSpace(strings.SplitN(accepted, ";", 2)[0])
+			if strings.EqualFold(accepted, mediaType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preference returns the value of the named preference in the request's Prefer headers (RFC 7240),
+// e.g. "representation" for "Prefer: return=representation".
+// Returns the empty string if the preference is not given.
+func preference(r *http.Request, name string) string {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			// Ignore any parameters of the preference
+			pref = strings.TrimSpace(strings.SplitN(pref, ";", 2)[0])
+			key, value := pref, ""
+			if i := strings.Index(pref, "="); i >= 0 {
+				key, value = strings.TrimSpace(pref[:i]), strings.Trim(strings.TrimSpace(pref[i+1:]), `"`)
+			}
+			if strings.EqualFold(key, name) {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// isAtomic returns true if the client asked for a batch to be applied all or nothing with the atomic=true query parameter.
+// Returns a 400 Bad Request if the atomic query parameter is malformed.
+func isAtomic(r *http.Request) (bool, int, error) {
+	v := r.URL.Query().Get("atomic")
+	if v == "" {
+		return false, 0, nil
+	}
+	atomic, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, http.StatusBadRequest, errors.New("atomic must be true or false")
+	}
+	return atomic, 0, nil
+}
+
+// ifMatchVersion sets the version that the Item is expected to be at from the request's If-Match header, if there is one:
+// the Item's ETag, as given by itemETag, e.g. `"3"`. An If-Match header of "*" matches the Item at any version.
+// A version in the Item's body must agree with the header.
+// Returns a 400 Bad Request if the header is neither a single ETag of an Item nor "*", or if it disagrees with the body.
+func ifMatchVersion(r *http.Request, item *models.Item) (int, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+	version, ok := parseItemETag(ifMatch)
+	if !ok {
+		return http.StatusBadRequest, models.NewFieldError("version", `If-Match must be the ETag of the item, e.g. "3", or *`)
+	}
+	if item.Version != 0 && item.Version != version {
+		return http.StatusBadRequest, models.NewFieldError("version", "If-Match gives version %d, but the body gives version %d", version, item.Version)
+	}
+	item.Version = version
+	return 0, nil
+}
+
+// ifMatchAny returns true if the request's If-Match header is "*", which only matches an Item that exists.
+func ifMatchAny(r *http.Request) bool {
+	return strings.TrimSpace(r.Header.Get("If-Match")) == "*"
+}
+
+// isUpsert returns true if the client opted in to upsert semantics with the "X-Upsert: true" header.
+func isUpsert(r *http.Request) bool {
+	upsert, _ := strconv.ParseBool(r.Header.Get("X-Upsert"))
+	return upsert
+}
+
+// decodeRequestItem decodes the json Item embedded in a Request and validates it for type errors.
+// The body is first validated against the Item schema published in the OpenAPI description, if the Server has one,
+// so that type errors are reported with the location of the offending value, e.g. "/quantity: expected integer, but got number".
+// Fields that are not part of an Item are rejected, so that typos do not go unnoticed.
+// Returns true if decoded successfully, false otherwise.
+func (s *Server) decodeRequestItem(w http.ResponseWriter, r *http.Request, item *models.Item) bool {
+	return s.decodeBody(w, r, item, true)
+}
+
+// decodeRequest decodes the json body of a Request into v.
+// Returns true if decoded successfully, false otherwise.
+func (s *Server) decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	return s.decodeBody(w, r, v, false)
+}
+
+// decodeBody decodes the json body of a Request into v, rejecting unknown fields if strict is true.
+// Strictly decoded bodies are Items and are first validated against the Item schema, as in validateItemSchema.
+// Bodies that are not declared as json by the Content-Type header are rejected with a 415 Unsupported Media Type
+// before they are read, and bodies larger than the configured maximum are rejected with a 413 Request Entity Too Large.
+// Returns true if decoded successfully, false otherwise.
+func (s *Server) decodeBody(w http.ResponseWriter, r *http.Request, v interface{}, strict bool) bool {
+	if !hasJSONBody(r) {
+		writeError(w, r, http.StatusUnsupportedMediaType, errors.New("requests must have the application/json Content-Type"))
+		return false
+	}
+
+	limit := s.config.MaxBodyBytes
+	if limit <= 0 {
+		limit = DEFAULT_MAX_BODY_BYTES
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		if isBodyTooLarge(err) {
+			writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("request body may not exceed %d bytes", limit))
+			return false
+		}
+		writeError(w, r, http.StatusBadRequest, err)
+		return false
+	}
+	if strict {
+		if err := s.validateItemSchema(body); err != nil {
+			writeError(w, r, http.StatusBadRequest, err)
+			return false
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		// Malformed request
+		writeError(w, r, http.StatusBadRequest, decodeError(err))
+		return false
+	}
+	return true
+}
+
+// hasJSONBody returns true if the request's Content-Type is application/json, or a json-based media type
+// such as application/merge-patch+json, with or without parameters, e.g. "application/json; charset=utf-8",
+// false otherwise, including if it has no Content-Type.
+func hasJSONBody(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// decodeError rewords json decoding errors that would otherwise expose the server's Go types.
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return fmt.Errorf("field %q must be %s", typeErr.Field, jsonType(typeErr.Type))
+	}
+	if msg := err.Error(); strings.HasPrefix(msg, "json: unknown field ") {
+		return fmt.Errorf("unknown field %s", strings.TrimPrefix(msg, "json: unknown field "))
+	}
+	return err
+}
+
+// jsonType describes the json type that decodes into a Go type, e.g. "a number" for an int.
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// isBodyTooLarge returns true if the error was caused by reading past the limit of an http.MaxBytesReader.
+func isBodyTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
+
+// changeReservation decodes and validates a reservation request and applies it with the given database method.
+func (s *Server) changeReservation(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id *models.ID, amount int) (int, error)) {
+	s.setHeader(w)
+	var adj models.Adjustment
+
+	// Decode and validate the request
+	if !s.decodeRequest(w, r, &adj) {
+		return
+	}
+	if code, err := adj.ValidateReservation(); err != nil {
+		writeError(w, r, code, err)
+		return
+	}
+
+	// Change reservation in database
+	id := models.ID(mux.Vars(r)["id"])
+	code, err := change(r.Context(), &id, *adj.Amount)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+
+	w.WriteHeader(code)
+}
+
+// step adjusts the quantity of an inventory Item by the "by" query parameter, 1 by default,
+// in the direction of sign.
+func (s *Server) step(w http.ResponseWriter, r *http.Request, sign int) {
+	s.setHeader(w)
+
+	by := 1
+	if v := r.URL.Query().Get("by"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, r, http.StatusBadRequest, errors.New("by must be a positive integer"))
+			return
+		}
+		by = n
+	}
+
+	// Adjust item in database
+	id := models.ID(mux.Vars(r)["id"])
+	change, code, err := s.db.AdjustQuantity(r.Context(), &id, sign*by)
+
+	if err != nil {
+		// Handle database errors
+		writeError(w, r, code, err)
+		return
+	}
+	s.notifyStock(r.Context(), models.EventItemAdjusted, change)
+
+	w.WriteHeader(code)
+}
+
+// notify reports a change to an Item to the webhook, if one is configured.
+// Unless the Item was deleted, it is read back from the primary database so that the Event carries its current state.
+// Failures are logged and never fail the request that made the change.
+func (s *Server) notify(ctx context.Context, eventType models.EventType, id models.ID) {
+	s.notifyStock(ctx, eventType, models.QuantityChange{ID: id})
+}
+
+// notifyStock reports a change to an Item that made the given change to its quantity, as notify does.
+// If the change brought the Item's quantity down to its reorder point from above it, an item.low_stock Event follows,
+// so that the alert is raised once on the way down rather than on every change while the Item's stock stays low.
+// The change must be the one read from the Item's row by the write itself, since the Item may have changed again since.
+func (s *Server) notifyStock(ctx context.Context, eventType models.EventType, change models.QuantityChange) {
+	if s.webhook == nil {
+		return
+	}
+	id := change.ID
+
+	event := models.Event{Type: eventType, ID: id, At: time.Now().UTC()}
+	if eventType == models.EventItemDeleted {
+		s.webhook.send(event)
+		return
+	}
+	item, _, err := s.db.GetItem(db.WithPrimary(ctx), &id)
+	if err != nil {
+		requestLog(ctx).Warn("webhook: dropping event", "event", eventType, "item_id", id, "error", err)
+		return
+	}
+	item.ComputeDerived()
+	event.Item = &item
+	s.webhook.send(event)
+
+	if change.CrossedReorderPoint(item.ReorderPoint) {
+		s.webhook.send(models.Event{Type: models.EventItemLowStock, ID: id, Item: &item, At: event.At})
+	}
+}
+
+// validateItem validates an Item embedded in a Request to ensure it adheres to API specification.
+// The Warnings for a valid Item are listed in the X-Validation-Warnings header; they do not change the response's status code,
+// and are removed by writeError if the Item cannot be written after all.
+// Returns true if the Item is valid, false otherwise.
+func (s *Server) validateItem(w http.ResponseWriter, r *http.Request, item *models.Item) bool {
+	if code, err := item.ValidateItem(); err != nil {
 		// Invalid Item in request
-		writeError(w, code, err)
+		writeError(w, r, code, err)
 		return false
 	}
+	for _, warning := range item.Warnings() {
+		w.Header().Add(VALIDATION_WARNINGS_HEADER, string(warning))
+	}
 	return true
 }
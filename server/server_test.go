@@ -1,11 +1,22 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/lbisceglia/shopify/db"
@@ -17,22 +28,23 @@ const (
 	PUT     = http.MethodPut
 	POST    = http.MethodPost
 	DELETE  = http.MethodDelete
+	PATCH   = http.MethodPatch
 	rootURL = "/api/items"
 )
 
-func Router(s InventoryServer) *mux.Router {
-	r := mux.NewRouter()
-	r.HandleFunc("/api/items", s.CreateItem).Methods(POST)
-	r.HandleFunc("/api/items/{id}", s.UpdateItem).Methods(PUT)
-	r.HandleFunc("/api/items/{id}", s.DeleteItem).Methods(DELETE)
-	r.HandleFunc("/api/items", s.GetItems).Methods(GET)
-	r.HandleFunc("/api/items/{id}", s.GetItem).Methods(GET)
-	return r
-}
-
 func Setup() *mux.Router {
 	s := NewServer(db.NewMockDB())
-	return Router(s)
+	return Routes(s)
+}
+
+func SetupWithConfig(config Config) *mux.Router {
+	s := &Server{
+		db:      db.NewMockDB(),
+		config:  config,
+		metrics: newMetrics(),
+		webhook: newWebhook(config.WebhookURL),
+	}
+	return Routes(s)
 }
 
 func InitHTTP(method string, url string, bodyMap map[string]interface{}) (*http.Request, *httptest.ResponseRecorder) {
@@ -42,6 +54,19 @@ func InitHTTP(method string, url string, bodyMap map[string]interface{}) (*http.
 	res := httptest.NewRecorder()
 	return req, res
 }
+
+// InitAdminHTTP creates a request carrying the given admin API key, if any.
+func InitAdminHTTP(method string, url string, body interface{}, key string) (*http.Request, *httptest.ResponseRecorder) {
+	b, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, url, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	res := httptest.NewRecorder()
+	return req, res
+}
+
 func TestGetItemsEmpty(t *testing.T) {
 	r := Setup()
 
@@ -146,6 +171,30 @@ func TestCreateAndGetItem(t *testing.T) {
 		t.Fatalf("got %v; want %v", len(location), 1)
 	}
 
+	// Check the response contains the created item
+	var created struct {
+		models.Item
+		DateAdded   *time.Time `json:"date_added"`
+		LastUpdated *time.Time `json:"last_updated"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &created); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+
+	id := models.ID(location[0][1:])
+	if created.ID != id {
+		t.Errorf(`expected created item to have id "%s" matching its location`, id)
+	}
+	if created.SKU != "AAAAAAAA" {
+		t.Errorf(`expected created item to have sku "AAAAAAAA"; got %s`, created.SKU)
+	}
+	if created.DateAdded == nil || created.LastUpdated == nil {
+		t.Fatal("expected created item to have date_added and last_updated")
+	}
+	if !created.DateAdded.Equal(*created.LastUpdated) {
+		t.Errorf("got %v; want %v", created.LastUpdated, created.DateAdded)
+	}
+
 	// Get the item
 	req, res = InitHTTP(GET, rootURL+location[0], nil)
 	r.ServeHTTP(res, req)
@@ -159,7 +208,6 @@ func TestCreateAndGetItem(t *testing.T) {
 		t.Errorf("got %v; want %v", got, want)
 	}
 
-	id := models.ID(location[0][1:])
 	if item.ID != id {
 		t.Errorf(`expected item to have id "%s" matching its location`, id)
 	}
@@ -174,6 +222,333 @@ func TestCreateAndGetItem(t *testing.T) {
 	}
 }
 
+func TestCreateItemPredictableID(t *testing.T) {
+	mock := db.NewMockDB()
+	mock.SetIDGenerator(models.NewSequenceIDGenerator(1))
+	r := Routes(NewServer(mock))
+
+	for _, want := range []models.ID{"00000000000000000001", "00000000000000000002"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "SKU-" + string(want[len(want)-4:]), "name": "Thing"})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Result().Header.Get("Location"), "/"+string(want); got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+		var item models.Item
+		if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+			t.Fatal("Parse JSON Data Error")
+		}
+		if got := item.ID; got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestUUIDItems(t *testing.T) {
+	t.Setenv("ID_FORMAT", "uuid")
+	defer func() { models.ItemIDFormat = models.XID_FORMAT }()
+	r := Setup()
+
+	// Create an item with a generated UUID
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+	r.ServeHTTP(res, req)
+
+	var item models.Item
+	if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := len(item.ID), models.UUID_LEN; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Upsert an item keyed by an upstream UUID
+	url := rootURL + "/f47ac10b-58cc-4372-a567-0e02b2c3d479"
+	req, res = InitHTTP(PUT, url, map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"})
+	req.Header.Set("X-Upsert", "true")
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	req, res = InitHTTP(GET, url, nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Upsert an item keyed by an xid
+	req, res = InitHTTP(PUT, rootURL+"/abcdefghijklmnopqrst", map[string]interface{}{"sku": "CCCCCCCC", "name": "Thing3"})
+	req.Header.Set("X-Upsert", "true")
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGetItemBySKU(t *testing.T) {
+	tests := map[string]struct {
+		sku       string
+		uppercase bool
+		code      int
+	}{
+		"existing sku":                 {sku: "AB-123", code: http.StatusOK},
+		"missing sku":                  {sku: "ZZ-999", code: http.StatusNotFound},
+		"wrong case":                   {sku: "ab-123", code: http.StatusNotFound},
+		"wrong case, uppercase stored": {sku: "ab-123", uppercase: true, code: http.StatusOK},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+			models.UppercaseSKU = test.uppercase
+			defer func() { models.UppercaseSKU = false }()
+
+			// Create the item
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AB-123", "name": "Thing1"})
+			r.ServeHTTP(res, req)
+			id := res.Result().Header.Get("Location")
+
+			// Look it up by SKU
+			req, res = InitHTTP(GET, rootURL+"/sku/"+test.sku, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.code != http.StatusOK {
+				return
+			}
+			var item models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := "/"+string(item.ID), id; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemByBarcode(t *testing.T) {
+	tests := map[string]struct {
+		barcode string
+		code    int
+	}{
+		"existing barcode":    {barcode: "4006381333931", code: http.StatusOK},
+		"missing barcode":     {barcode: "9780201379624", code: http.StatusNotFound},
+		"invalid check digit": {barcode: "4006381333932", code: http.StatusBadRequest},
+		"invalid length":      {barcode: "400638133393", code: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the items, only the first of which has a barcode
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "barcode": "4006381333931"})
+			r.ServeHTTP(res, req)
+			id := res.Result().Header.Get("Location")
+			req, res = InitHTTP(POST, rootURL, map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"})
+			r.ServeHTTP(res, req)
+
+			// Look it up by barcode
+			req, res = InitHTTP(GET, rootURL+"/barcode/"+test.barcode, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.code != http.StatusOK {
+				return
+			}
+			var item models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := "/"+string(item.ID), id; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := item.Barcode, test.barcode; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSearchItems(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		want  []string
+		code  int
+	}{
+		"single word":      {query: "?q=headphones", want: []string{"Wireless Headphones", "Earbuds"}, code: http.StatusOK},
+		"several words":    {query: "?q=wireless+headphones", want: []string{"Wireless Headphones", "Earbuds"}, code: http.StatusOK},
+		"case insensitive": {query: "?q=SPEAKER", want: []string{"Speaker"}, code: http.StatusOK},
+		"limit":            {query: "?q=headphones&limit=1", want: []string{"Wireless Headphones"}, code: http.StatusOK},
+		"no match":         {query: "?q=toaster", want: []string{}, code: http.StatusOK},
+		"missing query":    {query: "", code: http.StatusBadRequest},
+		"blank query":      {query: "?q=+", code: http.StatusBadRequest},
+		"invalid limit":    {query: "?q=headphones&limit=0", code: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			items := []map[string]interface{}{
+				{"sku": "AAAAAAAA", "name": "Wireless Headphones", "description": "Over-ear headphones"},
+				{"sku": "BBBBBBBB", "name": "Earbuds", "description": "Wireless alternative to headphones"},
+				{"sku": "CCCCCCCC", "name": "Speaker", "description": "Portable speaker"},
+			}
+			for _, item := range items {
+				req, res := InitHTTP(POST, rootURL, item)
+				r.ServeHTTP(res, req)
+			}
+
+			req, res := InitHTTP(GET, rootURL+"/search"+test.query, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.code != http.StatusOK {
+				return
+			}
+			var got []models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			names := []string{}
+			for _, item := range got {
+				names = append(names, item.Name)
+			}
+			if !reflect.DeepEqual(names, test.want) {
+				t.Errorf("got %v; want %v", names, test.want)
+			}
+		})
+	}
+}
+
+func TestCreateItemInvalidBarcode(t *testing.T) {
+	r := Setup()
+
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "barcode": "4006381333932"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestImageURL(t *testing.T) {
+	tests := map[string]struct {
+		imageURL string
+		want     string
+		code     int
+	}{
+		"no image url":    {imageURL: "", want: "", code: http.StatusNoContent},
+		"https image url": {imageURL: "https://cdn.example.com/thing.png", want: "https://cdn.example.com/thing.png", code: http.StatusNoContent},
+		"relative url":    {imageURL: "/images/thing.png", code: http.StatusBadRequest},
+		"non-http scheme": {imageURL: "ftp://example.com/thing.png", code: http.StatusBadRequest},
+		"data url":        {imageURL: "data:image/png;base64,iVBORw0KGgo=", code: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the item with an image
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "image_url": "http://example.com/old.png"})
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			location := res.Result().Header.Get("Location")
+
+			// Replace the image
+			body := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}
+			if test.imageURL != "" {
+				body["image_url"] = test.imageURL
+			}
+			req, res = InitHTTP(PUT, rootURL+location, body)
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.code != http.StatusNoContent {
+				return
+			}
+
+			// Read it back
+			req, res = InitHTTP(GET, rootURL+location, nil)
+			r.ServeHTTP(res, req)
+			var item models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := item.ImageURL, test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestImages(t *testing.T) {
+	tests := map[string]struct {
+		images []interface{}
+		want   []string
+		code   int
+	}{
+		"no images":       {images: nil, want: nil, code: http.StatusNoContent},
+		"replace images":  {images: []interface{}{"https://cdn.example.com/c.png", "https://cdn.example.com/a.png"}, want: []string{"https://cdn.example.com/c.png", "https://cdn.example.com/a.png"}, code: http.StatusNoContent},
+		"one bad image":   {images: []interface{}{"https://cdn.example.com/c.png", "c.png"}, code: http.StatusBadRequest},
+		"too many images": {images: make([]interface{}, models.MAX_IMAGES+1), code: http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the item with a gallery
+			old := []string{"https://cdn.example.com/a.png", "https://cdn.example.com/b.png"}
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "images": old})
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			location := res.Result().Header.Get("Location")
+
+			// Replace the gallery
+			body := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}
+			if test.images != nil {
+				body["images"] = test.images
+			}
+			req, res = InitHTTP(PUT, rootURL+location, body)
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			// Read it back; a rejected update leaves the gallery untouched
+			want := test.want
+			if test.code != http.StatusNoContent {
+				want = old
+			}
+			req, res = InitHTTP(GET, rootURL+location, nil)
+			r.ServeHTTP(res, req)
+			var item models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if !reflect.DeepEqual(item.Images, want) {
+				t.Errorf("got %v; want %v", item.Images, want)
+			}
+		})
+	}
+}
+
 func TestGetItemNotFound(t *testing.T) {
 	// Get non-existent item at /api/items/00000000000000000000
 	r := Setup()
@@ -186,6 +561,124 @@ func TestGetItemNotFound(t *testing.T) {
 	}
 }
 
+func TestErrorNegotiation(t *testing.T) {
+	tests := map[string]struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		"no accept header":        {accept: "", contentType: "application/json", body: `"there is no item with ID 00000000000000000000"`},
+		"json":                    {accept: "application/json", contentType: "application/json", body: `"there is no item with ID 00000000000000000000"`},
+		"any":                     {accept: "*/*", contentType: "application/json", body: `"there is no item with ID 00000000000000000000"`},
+		"plain text":              {accept: "text/plain", contentType: "text/plain; charset=utf-8", body: "there is no item with ID 00000000000000000000"},
+		"any text":                {accept: "text/*", contentType: "text/plain; charset=utf-8", body: "there is no item with ID 00000000000000000000"},
+		"json and plain text tie": {accept: "text/plain, application/json", contentType: "application/json", body: `"there is no item with ID 00000000000000000000"`},
+		"plain text preferred":    {accept: "application/json;q=0.5, text/plain", contentType: "text/plain; charset=utf-8", body: "there is no item with ID 00000000000000000000"},
+		"json preferred":          {accept: "text/plain;q=0.2, */*;q=0.5", contentType: "application/json", body: `"there is no item with ID 00000000000000000000"`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			req, res := InitHTTP(GET, rootURL+"/00000000000000000000", nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusNotFound; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := res.Header().Get("Content-Type"), test.contentType; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := res.Body.String(), test.body; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := map[string]struct {
+		method string
+		url    string
+		body   map[string]interface{}
+		code   int
+	}{
+		"create item":            {method: POST, url: rootURL, body: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusCreated},
+		"get items":              {method: GET, url: rootURL, code: http.StatusOK},
+		"get items after":        {method: GET, url: rootURL + "?after=", code: http.StatusOK},
+		"get item":               {method: GET, url: rootURL + "/{id}", code: http.StatusOK},
+		"get item by sku":        {method: GET, url: rootURL + "/sku/AAAAAAAA", code: http.StatusOK},
+		"get tags":               {method: GET, url: rootURL + "/tags", code: http.StatusOK},
+		"get deleted items":      {method: GET, url: rootURL + "/deleted", code: http.StatusOK},
+		"get item history":       {method: GET, url: rootURL + "/{id}/history", code: http.StatusOK},
+		"version":                {method: GET, url: "/version", code: http.StatusOK},
+		"create invalid item":    {method: POST, url: rootURL, body: map[string]interface{}{"sku": "BBBBBBBB"}, code: http.StatusBadRequest},
+		"create duplicate sku":   {method: POST, url: rootURL, body: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing2"}, code: http.StatusConflict},
+		"get missing item":       {method: GET, url: rootURL + "/00000000000000000000", code: http.StatusNotFound},
+		"get items bad filter":   {method: GET, url: rootURL + "?min_value=abc", code: http.StatusBadRequest},
+		"get items after offset": {method: GET, url: rootURL + "?after=&offset=1", code: http.StatusBadRequest},
+		"update missing item":    {method: PUT, url: rootURL + "/00000000000000000000", body: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusNotFound},
+		"admin disabled":         {method: POST, url: "/api/admin/import", body: map[string]interface{}{}, code: http.StatusForbidden},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the item
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+			r.ServeHTTP(res, req)
+			id := strings.TrimPrefix(res.Result().Header.Get("Location"), "/")
+
+			req, res = InitHTTP(test.method, strings.Replace(test.url, "{id}", id, 1), test.body)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := res.Header().Get("Content-Type"), "application/json"; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestContentTypeUnauthorized(t *testing.T) {
+	r := SetupWithConfig(Config{APIKeys: []string{"secret"}})
+
+	req, res := InitHTTP(GET, rootURL, nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusUnauthorized; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestWriteErrorDropsETag(t *testing.T) {
+	req, res := InitHTTP(GET, rootURL, nil)
+
+	// The ETag of the successful response has already been set when the error occurs
+	res.Header().Set("ETag", `W/"abc"`)
+	writeError(res, req, http.StatusInternalServerError, fmt.Errorf("cannot stream items"))
+
+	if got, want := res.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got := res.Header().Get("ETag"); got != "" {
+		t.Errorf("got %v; want no ETag", got)
+	}
+	if got, want := res.Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
 func TestDeleteExistingItem(t *testing.T) {
 	r := Setup()
 
@@ -233,63 +726,272 @@ func TestDeleteItemNotFound(t *testing.T) {
 	}
 }
 
-func TestCreateItemInvalid(t *testing.T) {
-	r := Setup()
-
-	// Attempt to create malformed items
-	tests := map[string]map[string]interface{}{
-		"no sku": {
-			"name": "Thing1",
-		},
-		"short sku": {
-			"sku":  "ABC",
-			"name": "Thing1",
-		},
-		"long sku": {
-			"sku":  "ZZZZZZZZZZZZZZZZZZZZ",
-			"name": "Thing1",
-		},
-		"invalid character in sku": {
-			"sku":  "AAAAAAA?",
-			"name": "Thing1",
-		},
-		"no name": {
-			"sku": "AAAAAAAA",
+func TestDeleteItemIfMatch(t *testing.T) {
+	tests := map[string]struct {
+		ifMatch func(etag string) string
+		code    int
+	}{
+		"no precondition": {
+			ifMatch: func(etag string) string { return "" },
+			code:    http.StatusNoContent,
 		},
-		"empty name": {
-			"sku":  "AAAAAAAA",
-			"name": "",
+		"matching etag": {
+			ifMatch: func(etag string) string { return etag },
+			code:    http.StatusNoContent,
 		},
-		"whitespace name": {
-			"sku":  "AAAAAAAA",
-			"name": "      ",
+		"one of several etags": {
+			ifMatch: func(etag string) string { return `"other", ` + etag },
+			code:    http.StatusNoContent,
 		},
-		"negative price": {
-			"sku":       "AAAAAAAA",
-			"name":      "Thing1",
-			"price_CAD": -0.01,
+		"any etag": {
+			ifMatch: func(etag string) string { return "*" },
+			code:    http.StatusNoContent,
 		},
-		"negative quantity": {
-			"sku":      "AAAAAAAA",
-			"name":     "Thing1",
-			"quantity": -1,
+		"stale etag": {
+			ifMatch: func(etag string) string { return `"stale"` },
+			code:    http.StatusPreconditionFailed,
 		},
-		"float quantity": {
-			"sku":      "AAAAAAAA",
-			"name":     "Thing1",
-			"quantity": 1.5,
+		"weak etag": {
+			ifMatch: func(etag string) string { return "W/" + etag },
+			code:    http.StatusPreconditionFailed,
 		},
 	}
 
-	for name, bodyMap := range tests {
+	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			req, res := InitHTTP(POST, rootURL, bodyMap)
+			r := Setup()
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
 			r.ServeHTTP(res, req)
+			url := rootURL + res.Header().Get("Location")
 
-			// Check the item was rejected
-			if got, want := res.Code, http.StatusBadRequest; got != want {
-				t.Errorf("got %v; want %v", got, want)
-			}
+			req, res = InitHTTP(GET, url, nil)
+			r.ServeHTTP(res, req)
+			etag := res.Header().Get("ETag")
+
+			req, res = InitHTTP(DELETE, url, nil)
+			if ifMatch := test.ifMatch(etag); ifMatch != "" {
+				req.Header.Set("If-Match", ifMatch)
+			}
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			// Check whether the item was deleted
+			want := http.StatusNotFound
+			if test.code == http.StatusPreconditionFailed {
+				want = http.StatusOK
+			}
+			req, res = InitHTTP(GET, url, nil)
+			r.ServeHTTP(res, req)
+			if got := res.Code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDeleteItemIfMatchCompressed(t *testing.T) {
+	r := Setup()
+	description := strings.Repeat("a thing worth compressing ", 50)
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "description": description})
+	r.ServeHTTP(res, req)
+	url := rootURL + res.Header().Get("Location")
+
+	// The ETag of the compressed item is the same as that of the uncompressed one
+	req, res = InitHTTP(GET, url, nil)
+	r.ServeHTTP(res, req)
+	etag := res.Header().Get("ETag")
+
+	req, res = InitHTTP(GET, url, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(res, req)
+	if got, want := res.Header().Get("Content-Encoding"), "gzip"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	compressed := res.Header().Get("ETag")
+	if compressed != etag {
+		t.Errorf("got %v; want %v", compressed, etag)
+	}
+
+	req, res = InitHTTP(DELETE, url, nil)
+	req.Header.Set("If-Match", compressed)
+	r.ServeHTTP(res, req)
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestDeleteItemIfMatchModified(t *testing.T) {
+	r := Setup()
+	bodyMap := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}
+	req, res := InitHTTP(POST, rootURL, bodyMap)
+	r.ServeHTTP(res, req)
+	url := rootURL + res.Header().Get("Location")
+
+	req, res = InitHTTP(GET, url, nil)
+	r.ServeHTTP(res, req)
+	etag := res.Header().Get("ETag")
+
+	// Someone else modifies the item
+	bodyMap["name"] = "Thing2"
+	req, res = InitHTTP(PUT, url, bodyMap)
+	r.ServeHTTP(res, req)
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	req, res = InitHTTP(DELETE, url, nil)
+	req.Header.Set("If-Match", etag)
+	r.ServeHTTP(res, req)
+	if got, want := res.Code, http.StatusPreconditionFailed; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// A precondition on a missing item fails
+	req, res = InitHTTP(DELETE, rootURL+"/00000000000000000000", nil)
+	req.Header.Set("If-Match", "*")
+	r.ServeHTTP(res, req)
+	if got, want := res.Code, http.StatusPreconditionFailed; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestDeleteItems(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	ids := []string{}
+	for _, sku := range []string{"AAAAAAAA", "BBBBBBBB", "CCCCCCCC"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing", "quantity": 5})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		ids = append(ids, res.Result().Header.Get("Location")[1:])
+	}
+
+	// Delete two of the items and a non-existent item
+	missing := "00000000000000000000"
+	req, res := InitHTTP(DELETE, rootURL+"?ids="+ids[0]+","+ids[1]+","+missing, nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var results map[models.ID]models.DeleteResult
+	if err := json.Unmarshal(res.Body.Bytes(), &results); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+
+	want := map[models.ID]models.DeleteResult{
+		models.ID(ids[0]):  models.DeleteResultDeleted,
+		models.ID(ids[1]):  models.DeleteResultDeleted,
+		models.ID(missing): models.DeleteResultNotFound,
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("got %v; want %v", results, want)
+	}
+
+	// Check that only the third item remains
+	req, res = InitHTTP(GET, rootURL, nil)
+	r.ServeHTTP(res, req)
+
+	var items []models.Item
+	if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if len(items) != 1 || items[0].ID != models.ID(ids[2]) {
+		t.Errorf("got %v; want %v", items, ids[2])
+	}
+}
+
+func TestDeleteItemsInvalid(t *testing.T) {
+	r := Setup()
+
+	tooMany := make([]string, models.BATCH_MAX_SIZE+1)
+	for i := range tooMany {
+		tooMany[i] = string(models.NewID())
+	}
+
+	tests := map[string]string{
+		"missing ids":  "",
+		"empty ids":    "?ids=",
+		"blank ids":    "?ids=,%20,",
+		"too many ids": "?ids=" + strings.Join(tooMany, ","),
+	}
+
+	for name, query := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(DELETE, rootURL+query, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCreateItemInvalid(t *testing.T) {
+	r := Setup()
+
+	// Attempt to create malformed items
+	tests := map[string]map[string]interface{}{
+		"no sku": {
+			"name": "Thing1",
+		},
+		"short sku": {
+			"sku":  "ABC",
+			"name": "Thing1",
+		},
+		"long sku": {
+			"sku":  "ZZZZZZZZZZZZZZZZZZZZ",
+			"name": "Thing1",
+		},
+		"invalid character in sku": {
+			"sku":  "AAAAAAA?",
+			"name": "Thing1",
+		},
+		"no name": {
+			"sku": "AAAAAAAA",
+		},
+		"empty name": {
+			"sku":  "AAAAAAAA",
+			"name": "",
+		},
+		"whitespace name": {
+			"sku":  "AAAAAAAA",
+			"name": "      ",
+		},
+		"negative price": {
+			"sku":       "AAAAAAAA",
+			"name":      "Thing1",
+			"price_CAD": -0.01,
+		},
+		"negative quantity": {
+			"sku":      "AAAAAAAA",
+			"name":     "Thing1",
+			"quantity": -1,
+		},
+		"float quantity": {
+			"sku":      "AAAAAAAA",
+			"name":     "Thing1",
+			"quantity": 1.5,
+		},
+	}
+
+	for name, bodyMap := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL, bodyMap)
+			r.ServeHTTP(res, req)
+
+			// Check the item was rejected
+			if got, want := res.Code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
 		})
 	}
 }
@@ -384,8 +1086,8 @@ func TestUpdateItem(t *testing.T) {
 	if item.Description != "First thing's first" {
 		t.Errorf(`expected item to have description "First thing's first"; got %s`, item.Description)
 	}
-	if *item.PriceInCAD != 15.00 {
-		t.Errorf(`expected item to have price 15.00; got %f`, *item.PriceInCAD)
+	if item.Price == nil || *item.Price != (models.Price{Amount: 15.00, Currency: "CAD"}) {
+		t.Errorf(`expected item to have price 15.00 CAD; got %v`, item.Price)
 	}
 	if *item.Quantity != 9 {
 		t.Errorf(`expected item to have quantity 9; got %d`, *item.Quantity)
@@ -432,8 +1134,8 @@ func TestUpdateItem(t *testing.T) {
 	if item.Description != "" {
 		t.Errorf(`expected item to have no description"; got %s`, item.Description)
 	}
-	if item.PriceInCAD != nil {
-		t.Errorf(`expected item to have no price; got %f`, *item.PriceInCAD)
+	if item.Price != nil {
+		t.Errorf(`expected item to have no price; got %v`, *item.Price)
 	}
 	if *item.Quantity != 0 {
 		t.Errorf(`expected item to have quantity 0; got %d`, *item.Quantity)
@@ -576,3 +1278,5497 @@ func TestUpdateItemDuplicateSKU(t *testing.T) {
 		t.Errorf("got %v; want %v", got, want)
 	}
 }
+
+func TestGetMarginReport(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	bodyMaps := []map[string]interface{}{
+		{
+			"sku":       "AAAAAAAA",
+			"name":      "Thing1",
+			"price_CAD": 15.00,
+			"cost_CAD":  10.00,
+		},
+		{
+			"sku":       "BBBBBBBB",
+			"name":      "Thing2",
+			"price_CAD": 5.00,
+			"cost_CAD":  7.50,
+		},
+		{
+			"sku":       "CCCCCCCC",
+			"name":      "Thing3",
+			"price_CAD": 100.00,
+		},
+	}
+
+	for _, bodyMap := range bodyMaps {
+		req, res := InitHTTP(POST, rootURL, bodyMap)
+		r.ServeHTTP(res, req)
+
+		// Check the item was created successfully
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	// Get the margin report
+	req, res := InitHTTP(GET, "/api/reports/margin", nil)
+	r.ServeHTTP(res, req)
+
+	var report models.MarginReport
+	if err := json.Unmarshal(res.Body.Bytes(), &report); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := len(report.Items), 3; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := report.TotalMargin, 2.50; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := report.Currency, models.COST_CURRENCY; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	for _, entry := range report.Items {
+		switch entry.SKU {
+		case "AAAAAAAA":
+			if entry.Margin == nil || *entry.Margin != 5.00 {
+				t.Errorf("expected item %s to have margin 5.00", entry.SKU)
+			}
+			if entry.NegativeMargin {
+				t.Errorf("expected item %s not to be flagged", entry.SKU)
+			}
+		case "BBBBBBBB":
+			if entry.Margin == nil || *entry.Margin != -2.50 {
+				t.Errorf("expected item %s to have margin -2.50", entry.SKU)
+			}
+			if !entry.NegativeMargin {
+				t.Errorf("expected item %s to be flagged", entry.SKU)
+			}
+		case "CCCCCCCC":
+			if entry.Margin != nil {
+				t.Errorf("expected item %s to have no margin", entry.SKU)
+			}
+		}
+	}
+}
+
+func TestGetReorderReport(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	bodyMaps := []map[string]interface{}{
+		{
+			"sku":              "AAAAAAAA",
+			"name":             "Thing1",
+			"quantity":         2,
+			"reorder_point":    5,
+			"reorder_quantity": 20,
+			"supplier":         map[string]interface{}{"name": "Acme"},
+		},
+		{
+			"sku":           "BBBBBBBB",
+			"name":          "Thing2",
+			"quantity":      5,
+			"reorder_point": 5,
+			"supplier":      map[string]interface{}{"name": "Globex"},
+		},
+		{
+			"sku":           "CCCCCCCC",
+			"name":          "Thing3",
+			"quantity":      10,
+			"reorder_point": 5,
+		},
+		{
+			"sku":      "DDDDDDDD",
+			"name":     "Thing4",
+			"quantity": 0,
+		},
+	}
+
+	for _, bodyMap := range bodyMaps {
+		req, res := InitHTTP(POST, rootURL, bodyMap)
+		r.ServeHTTP(res, req)
+
+		// Check the item was created successfully
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	tests := map[string]struct {
+		url  string
+		want map[string]int
+	}{
+		"all suppliers": {
+			url:  "/api/items/reorder",
+			want: map[string]int{"AAAAAAAA": 20, "BBBBBBBB": 1},
+		},
+		"by supplier": {
+			url:  "/api/items/reorder?supplier=acme",
+			want: map[string]int{"AAAAAAAA": 20},
+		},
+		"unknown supplier": {
+			url:  "/api/items/reorder?supplier=Initech",
+			want: map[string]int{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, test.url, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			var report []models.ReorderEntry
+			if err := json.Unmarshal(res.Body.Bytes(), &report); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			got := make(map[string]int)
+			for _, entry := range report {
+				got[string(entry.SKU)] = entry.ReorderQuantity
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestVerboseErrors(t *testing.T) {
+	tests := map[string]struct {
+		verbose bool
+		want    string
+	}{
+		"verbose":     {verbose: true, want: "there is already an item with SKU AAAAAAAA"},
+		"not verbose": {verbose: false, want: "SKU already in use"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := SetupWithConfig(Config{VerboseErrors: test.verbose})
+			bodyMap := map[string]interface{}{
+				"sku":  "AAAAAAAA",
+				"name": "Thing1",
+			}
+
+			req, res := InitHTTP(POST, rootURL, bodyMap)
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			// Create an item with the same SKU
+			req, res = InitHTTP(POST, rootURL, bodyMap)
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, http.StatusConflict; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			var msg string
+			if err := json.Unmarshal(res.Body.Bytes(), &msg); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := msg, test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := res.Header().Get("X-Error-Field"), "sku"; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCreateItemNegativeCost(t *testing.T) {
+	r := Setup()
+
+	bodyMap := map[string]interface{}{
+		"sku":      "AAAAAAAA",
+		"name":     "Thing1",
+		"cost_CAD": -0.01,
+	}
+
+	req, res := InitHTTP(POST, rootURL, bodyMap)
+	r.ServeHTTP(res, req)
+
+	// Check the item was rejected
+	if got, want := res.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestImportItems(t *testing.T) {
+	r := SetupWithConfig(Config{AdminAPIKey: "secret"})
+
+	// Import the items
+	body := []map[string]interface{}{
+		{
+			"id":   "00000000000000000001",
+			"sku":  "AAAAAAAA",
+			"name": "Thing1",
+		},
+		{
+			"id":        "00000000000000000002",
+			"sku":       "BBBBBBBB",
+			"name":      "Thing2",
+			"price_CAD": 15.00,
+			"quantity":  5,
+		},
+	}
+	req, res := InitAdminHTTP(POST, "/api/admin/import", body, "secret")
+	r.ServeHTTP(res, req)
+
+	// Check the items were imported successfully
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// Check the items kept their IDs
+	for _, id := range []string{"00000000000000000001", "00000000000000000002"} {
+		req, res = InitHTTP(GET, rootURL+"/"+id, nil)
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusOK; got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestImportItemsCollision(t *testing.T) {
+	r := SetupWithConfig(Config{AdminAPIKey: "secret"})
+
+	// Import the first item
+	body := []map[string]interface{}{
+		{
+			"id":   "00000000000000000001",
+			"sku":  "AAAAAAAA",
+			"name": "Thing1",
+		},
+	}
+	req, res := InitAdminHTTP(POST, "/api/admin/import", body, "secret")
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// Import a batch that collides with the first item's ID
+	body = []map[string]interface{}{
+		{
+			"id":   "00000000000000000002",
+			"sku":  "BBBBBBBB",
+			"name": "Thing2",
+		},
+		{
+			"id":   "00000000000000000001",
+			"sku":  "CCCCCCCC",
+			"name": "Thing3",
+		},
+	}
+	req, res = InitAdminHTTP(POST, "/api/admin/import", body, "secret")
+	r.ServeHTTP(res, req)
+
+	// Check the batch was rejected
+	if got, want := res.Code, http.StatusConflict; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Check that no part of the batch was imported
+	req, res = InitHTTP(GET, rootURL, nil)
+	r.ServeHTTP(res, req)
+
+	var items []models.Item
+	if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := len(items), 1; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestImportItemsInvalid(t *testing.T) {
+	r := SetupWithConfig(Config{AdminAPIKey: "secret"})
+
+	tests := map[string][]map[string]interface{}{
+		"no id": {
+			{
+				"sku":  "AAAAAAAA",
+				"name": "Thing1",
+			},
+		},
+		"malformed id": {
+			{
+				"id":   "not-a-real-ID",
+				"sku":  "AAAAAAAA",
+				"name": "Thing1",
+			},
+		},
+		"invalid item": {
+			{
+				"id":  "00000000000000000001",
+				"sku": "AAAAAAAA",
+			},
+		},
+		"shared sku": {
+			{
+				"id":   "00000000000000000001",
+				"sku":  "AAAAAAAA",
+				"name": "Thing1",
+			},
+			{
+				"id":   "00000000000000000002",
+				"sku":  "aaaaaaaa",
+				"name": "Thing2",
+			},
+		},
+	}
+
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitAdminHTTP(POST, "/api/admin/import", body, "secret")
+			r.ServeHTTP(res, req)
+
+			// Check the batch was rejected
+			if got, want := res.Code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestImportItemsUnauthorized(t *testing.T) {
+	body := []map[string]interface{}{
+		{
+			"id":   "00000000000000000001",
+			"sku":  "AAAAAAAA",
+			"name": "Thing1",
+		},
+	}
+
+	tests := map[string]struct {
+		config Config
+		key    string
+		code   int
+	}{
+		"missing key": {
+			config: Config{AdminAPIKey: "secret"},
+			key:    "",
+			code:   http.StatusUnauthorized,
+		},
+		"wrong key": {
+			config: Config{AdminAPIKey: "secret"},
+			key:    "guess",
+			code:   http.StatusForbidden,
+		},
+		"admin disabled": {
+			config: Config{},
+			key:    "secret",
+			code:   http.StatusForbidden,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := SetupWithConfig(test.config)
+
+			req, res := InitAdminHTTP(POST, "/api/admin/import", body, test.key)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	item := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 1}
+	keys := []string{"key1", "key2"}
+	readOnly := []string{"reader"}
+	batch := map[string]interface{}{"ids": []string{"00000000000000000000"}}
+
+	tests := map[string]struct {
+		config Config
+		method string
+		url    string
+		body   interface{}
+		key    string
+		code   int
+	}{
+		"no keys configured": {
+			config: Config{},
+			method: POST, url: rootURL, body: item, key: "",
+			code: http.StatusCreated,
+		},
+		"missing key": {
+			config: Config{APIKeys: keys},
+			method: POST, url: rootURL, body: item, key: "",
+			code: http.StatusUnauthorized,
+		},
+		"wrong key": {
+			config: Config{APIKeys: keys},
+			method: POST, url: rootURL, body: item, key: "guess",
+			code: http.StatusForbidden,
+		},
+		"first key": {
+			config: Config{APIKeys: keys},
+			method: POST, url: rootURL, body: item, key: "key1",
+			code: http.StatusCreated,
+		},
+		"second key": {
+			config: Config{APIKeys: keys},
+			method: POST, url: rootURL, body: item, key: "key2",
+			code: http.StatusCreated,
+		},
+		"read without key": {
+			config: Config{APIKeys: keys},
+			method: GET, url: rootURL, body: nil, key: "",
+			code: http.StatusUnauthorized,
+		},
+		"public read without key": {
+			config: Config{APIKeys: keys, PublicReads: true},
+			method: GET, url: rootURL, body: nil, key: "",
+			code: http.StatusOK,
+		},
+		"public reads do not open writes": {
+			config: Config{APIKeys: keys, PublicReads: true},
+			method: DELETE, url: rootURL + "/00000000000000000000", body: nil, key: "",
+			code: http.StatusUnauthorized,
+		},
+		"read-only key reads": {
+			config: Config{APIKeys: keys, ReadOnlyAPIKeys: readOnly},
+			method: GET, url: rootURL, body: nil, key: "reader",
+			code: http.StatusOK,
+		},
+		"read-only key reads in batch": {
+			config: Config{APIKeys: keys, ReadOnlyAPIKeys: readOnly},
+			method: POST, url: rootURL + "/batch-get", body: batch, key: "reader",
+			code: http.StatusOK,
+		},
+		"read-only key writes": {
+			config: Config{APIKeys: keys, ReadOnlyAPIKeys: readOnly},
+			method: POST, url: rootURL, body: item, key: "reader",
+			code: http.StatusForbidden,
+		},
+		"read-only key deletes": {
+			config: Config{ReadOnlyAPIKeys: readOnly},
+			method: DELETE, url: rootURL + "/00000000000000000000", body: nil, key: "reader",
+			code: http.StatusForbidden,
+		},
+		"only read-only keys configured": {
+			config: Config{ReadOnlyAPIKeys: readOnly},
+			method: GET, url: rootURL, body: nil, key: "",
+			code: http.StatusUnauthorized,
+		},
+		"read-write key reads": {
+			config: Config{APIKeys: keys, ReadOnlyAPIKeys: readOnly},
+			method: GET, url: rootURL, body: nil, key: "key1",
+			code: http.StatusOK,
+		},
+		"admin endpoint uses admin key": {
+			config: Config{APIKeys: keys, AdminAPIKey: "secret", BasePath: DEFAULT_BASE_PATH},
+			method: GET, url: "/api/admin/sku-normalization/preview", body: nil, key: "secret",
+			code: http.StatusOK,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := SetupWithConfig(test.config)
+
+			req, res := InitAdminHTTP(test.method, test.url, test.body, test.key)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if res.Code == http.StatusUnauthorized && res.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("got %q; want %q", res.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func TestAuthenticateRole(t *testing.T) {
+	s := &Server{config: Config{APIKeys: []string{"writer"}, ReadOnlyAPIKeys: []string{"reader"}}}
+
+	tests := map[string]struct {
+		key  string
+		role Role
+	}{
+		"read-write key": {key: "writer", role: RoleReadWrite},
+		"read-only key":  {key: "reader", role: RoleReadOnly},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var role Role
+			var ok bool
+			h := s.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				role, ok = RoleFromContext(r.Context())
+			}))
+
+			req, res := InitAdminHTTP(GET, rootURL, nil, test.key)
+			h.ServeHTTP(res, req)
+
+			if !ok {
+				t.Fatalf("expected a role in the request context")
+			}
+			if got, want := role, test.role; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestItemHistory(t *testing.T) {
+	r := Setup()
+
+	// STEP 1
+	// Create the item
+	bodyMap := map[string]interface{}{
+		"sku":      "AAAAAAAA",
+		"name":     "Thing1",
+		"quantity": 5,
+	}
+
+	req, res := InitHTTP(POST, rootURL, bodyMap)
+	r.ServeHTTP(res, req)
+
+	// Check the item was created successfully
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	header := res.Result().Header
+	location := header.Values("Location")
+
+	if location == nil || len(location) != 1 {
+		t.Fatalf("got %v; want %v", len(location), 1)
+	}
+
+	// STEP 2
+	// Update the item's quantity
+	bodyMap["quantity"] = 8
+	req, res = InitHTTP(PUT, rootURL+location[0], bodyMap)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// STEP 3
+	// Remove three units
+	req, res = InitHTTP(POST, rootURL+location[0]+"/adjust", map[string]interface{}{"amount": -3})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Attempt to remove more units than are in stock
+	req, res = InitHTTP(POST, rootURL+location[0]+"/adjust", map[string]interface{}{"amount": -10})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusConflict; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// STEP 4
+	// Get the item's history
+	req, res = InitHTTP(GET, rootURL+location[0]+"/history", nil)
+	r.ServeHTTP(res, req)
+
+	var history []models.HistoryEntry
+	if err := json.Unmarshal(res.Body.Bytes(), &history); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	want := []models.HistoryEntry{
+		{OldQuantity: 0, NewQuantity: 5, Operation: models.OperationCreate},
+		{OldQuantity: 5, NewQuantity: 8, Operation: models.OperationUpdate},
+		{OldQuantity: 8, NewQuantity: 5, Operation: models.OperationAdjust},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("got %v; want %v", len(history), len(want))
+	}
+
+	id := models.ID(location[0][1:])
+	for i, entry := range history {
+		if entry.ItemID != id {
+			t.Errorf(`expected entry %d to have item id "%s"; got %s`, i, id, entry.ItemID)
+		}
+		if entry.OldQuantity != want[i].OldQuantity || entry.NewQuantity != want[i].NewQuantity {
+			t.Errorf("expected entry %d to change quantity from %d to %d; got %d to %d",
+				i, want[i].OldQuantity, want[i].NewQuantity, entry.OldQuantity, entry.NewQuantity)
+		}
+		if entry.Operation != want[i].Operation {
+			t.Errorf(`expected entry %d to have operation "%s"; got %s`, i, want[i].Operation, entry.Operation)
+		}
+	}
+}
+
+func TestItemHistoryNotFound(t *testing.T) {
+	r := Setup()
+
+	// Get the history of the non-existent item at /api/items/00000000000000000000
+	req, res := InitHTTP(GET, rootURL+"/00000000000000000000/history", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestStocktake(t *testing.T) {
+	tests := map[string]struct {
+		url     string
+		counts  []map[string]interface{}
+		code    int
+		want    map[string]int
+		missing []models.SKU
+	}{
+		"counts applied": {
+			url:     "/api/items/stocktake",
+			counts:  []map[string]interface{}{{"sku": "AAAAAAAA", "quantity": 3}, {"sku": "BBBBBBBB", "quantity": 5}},
+			code:    http.StatusOK,
+			want:    map[string]int{"AAAAAAAA": 3, "BBBBBBBB": 5},
+			missing: []models.SKU{},
+		},
+		"missing sku reported": {
+			url:     "/api/items/stocktake",
+			counts:  []map[string]interface{}{{"sku": "AAAAAAAA", "quantity": 3}, {"sku": "ZZZZZZZZ", "quantity": 1}},
+			code:    http.StatusOK,
+			want:    map[string]int{"AAAAAAAA": 3, "BBBBBBBB": 5},
+			missing: []models.SKU{"ZZZZZZZZ"},
+		},
+		"missing sku atomic": {
+			url:    "/api/items/stocktake?atomic=true",
+			counts: []map[string]interface{}{{"sku": "AAAAAAAA", "quantity": 3}, {"sku": "ZZZZZZZZ", "quantity": 1}},
+			code:   http.StatusNotFound,
+			want:   map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+		"invalid atomic": {
+			url:    "/api/items/stocktake?atomic=maybe",
+			counts: []map[string]interface{}{{"sku": "AAAAAAAA", "quantity": 3}},
+			code:   http.StatusBadRequest,
+			want:   map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+		"invalid negative quantity": {
+			url:    "/api/items/stocktake",
+			counts: []map[string]interface{}{{"sku": "AAAAAAAA", "quantity": -3}},
+			code:   http.StatusBadRequest,
+			want:   map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+			for _, bodyMap := range []map[string]interface{}{
+				{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 10},
+				{"sku": "BBBBBBBB", "name": "Thing2", "quantity": 5},
+			} {
+				req, res := InitHTTP(POST, rootURL, bodyMap)
+				r.ServeHTTP(res, req)
+				if got, want := res.Code, http.StatusCreated; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+			}
+
+			req, res := InitAdminHTTP(POST, test.url, test.counts, "")
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.code == http.StatusOK {
+				var result models.StocktakeResult
+				if err := json.Unmarshal(res.Body.Bytes(), &result); err != nil {
+					t.Fatal("Parse JSON Data Error")
+				}
+				if got, want := result.Missing, test.missing; !reflect.DeepEqual(got, want) {
+					t.Errorf("got %v; want %v", got, want)
+				}
+				if got, want := result.Counted[0].Delta, -7; got != want {
+					t.Errorf("got %v; want %v", got, want)
+				}
+			}
+
+			// Check the quantities of the items
+			for sku, quantity := range test.want {
+				req, res := InitHTTP(GET, rootURL+"/sku/"+sku, nil)
+				r.ServeHTTP(res, req)
+				var item models.Item
+				if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+					t.Fatal("Parse JSON Data Error")
+				}
+				if got, want := *item.Quantity, quantity; got != want {
+					t.Errorf("got %v; want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateItems(t *testing.T) {
+	tests := map[string]struct {
+		url      string
+		updates  []map[string]interface{}
+		code     int
+		statuses []int
+		fields   []string
+		want     map[string]int
+	}{
+		"all updated": {
+			url:      "/api/items/bulk",
+			updates:  []map[string]interface{}{{"id": "A", "sku": "AAAAAAAA", "name": "Thing1", "quantity": 3}, {"id": "B", "sku": "BBBBBBBB", "name": "Thing2", "quantity": 4}},
+			code:     http.StatusOK,
+			statuses: []int{http.StatusNoContent, http.StatusNoContent},
+			fields:   []string{"", ""},
+			want:     map[string]int{"AAAAAAAA": 3, "BBBBBBBB": 4},
+		},
+		"missing item skipped": {
+			url:      "/api/items/bulk",
+			updates:  []map[string]interface{}{{"id": "00000000000000000000", "sku": "ZZZZZZZZ", "name": "Thing9", "quantity": 1}, {"id": "B", "sku": "BBBBBBBB", "name": "Thing2", "quantity": 4}},
+			code:     http.StatusOK,
+			statuses: []int{http.StatusNotFound, http.StatusNoContent},
+			fields:   []string{"", ""},
+			want:     map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 4},
+		},
+		"sku conflict skipped": {
+			url:      "/api/items/bulk",
+			updates:  []map[string]interface{}{{"id": "A", "sku": "BBBBBBBB", "name": "Thing1", "quantity": 3}, {"id": "B", "sku": "DDDDDDDD", "name": "Thing2", "quantity": 4}},
+			code:     http.StatusOK,
+			statuses: []int{http.StatusConflict, http.StatusNoContent},
+			fields:   []string{"sku", ""},
+			want:     map[string]int{"AAAAAAAA": 10, "DDDDDDDD": 4},
+		},
+		"invalid sku shared within batch": {
+			url:     "/api/items/bulk",
+			updates: []map[string]interface{}{{"id": "A", "sku": "CCCCCCCC", "name": "Thing1", "quantity": 3}, {"id": "B", "sku": "cccccccc", "name": "Thing2", "quantity": 4}},
+			code:    http.StatusBadRequest,
+			want:    map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+		"atomic": {
+			url:      "/api/items/bulk?atomic=true",
+			updates:  []map[string]interface{}{{"id": "A", "sku": "AAAAAAAA", "name": "Thing1", "quantity": 3}, {"id": "B", "sku": "BBBBBBBB", "name": "Thing2", "quantity": 4}},
+			code:     http.StatusOK,
+			statuses: []int{http.StatusNoContent, http.StatusNoContent},
+			fields:   []string{"", ""},
+			want:     map[string]int{"AAAAAAAA": 3, "BBBBBBBB": 4},
+		},
+		"atomic missing item": {
+			url:     "/api/items/bulk?atomic=true",
+			updates: []map[string]interface{}{{"id": "A", "sku": "AAAAAAAA", "name": "Thing1", "quantity": 3}, {"id": "00000000000000000000", "sku": "ZZZZZZZZ", "name": "Thing9", "quantity": 1}},
+			code:    http.StatusNotFound,
+			want:    map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+		"atomic sku conflict": {
+			url:     "/api/items/bulk?atomic=true",
+			updates: []map[string]interface{}{{"id": "B", "sku": "DDDDDDDD", "name": "Thing2", "quantity": 4}, {"id": "A", "sku": "BBBBBBBB", "name": "Thing1", "quantity": 3}},
+			code:    http.StatusConflict,
+			want:    map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+		"invalid atomic": {
+			url:     "/api/items/bulk?atomic=maybe",
+			updates: []map[string]interface{}{{"id": "A", "sku": "AAAAAAAA", "name": "Thing1", "quantity": 3}},
+			code:    http.StatusBadRequest,
+			want:    map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+		"invalid missing id": {
+			url:     "/api/items/bulk",
+			updates: []map[string]interface{}{{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 3}},
+			code:    http.StatusBadRequest,
+			want:    map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+		"invalid item": {
+			url:     "/api/items/bulk",
+			updates: []map[string]interface{}{{"id": "A", "sku": "AAAAAAAA", "name": "Thing1", "quantity": -3}},
+			code:    http.StatusBadRequest,
+			want:    map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+		"invalid empty": {
+			url:     "/api/items/bulk",
+			updates: []map[string]interface{}{},
+			code:    http.StatusBadRequest,
+			want:    map[string]int{"AAAAAAAA": 10, "BBBBBBBB": 5},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+			ids := map[string]string{}
+			for key, bodyMap := range map[string]map[string]interface{}{
+				"A": {"sku": "AAAAAAAA", "name": "Thing1", "quantity": 10},
+				"B": {"sku": "BBBBBBBB", "name": "Thing2", "quantity": 5},
+			} {
+				req, res := InitHTTP(POST, rootURL, bodyMap)
+				r.ServeHTTP(res, req)
+				if got, want := res.Code, http.StatusCreated; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				ids[key] = strings.TrimPrefix(res.Header().Get("Location"), "/")
+			}
+			for _, update := range test.updates {
+				if id, ok := ids[fmt.Sprint(update["id"])]; ok {
+					update["id"] = id
+				}
+			}
+
+			req, res := InitAdminHTTP(PUT, test.url, test.updates, "")
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.code == http.StatusOK {
+				var results []models.UpdateResult
+				if err := json.Unmarshal(res.Body.Bytes(), &results); err != nil {
+					t.Fatal("Parse JSON Data Error")
+				}
+				if got, want := len(results), len(test.updates); got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				for i, result := range results {
+					if got, want := string(result.ID), test.updates[i]["id"]; got != want {
+						t.Errorf("got %v; want %v", got, want)
+					}
+					if got, want := result.Status, test.statuses[i]; got != want {
+						t.Errorf("got %v; want %v", got, want)
+					}
+					if got, want := result.Field, test.fields[i]; got != want {
+						t.Errorf("got %v; want %v", got, want)
+					}
+				}
+			}
+
+			// Check the quantities of the items
+			for sku, quantity := range test.want {
+				req, res := InitHTTP(GET, rootURL+"/sku/"+sku, nil)
+				r.ServeHTTP(res, req)
+				var item models.Item
+				if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+					t.Fatal("Parse JSON Data Error")
+				}
+				if got, want := *item.Quantity, quantity; got != want {
+					t.Errorf("got %v; want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestStocktakeHistory(t *testing.T) {
+	r := Setup()
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 10})
+	r.ServeHTTP(res, req)
+	location := res.Header().Get("Location")
+
+	req, res = InitAdminHTTP(POST, "/api/items/stocktake", []map[string]interface{}{{"sku": "AAAAAAAA", "quantity": 12}}, "")
+	r.ServeHTTP(res, req)
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// A count that matches the recorded quantity is not recorded
+	req, res = InitAdminHTTP(POST, "/api/items/stocktake", []map[string]interface{}{{"sku": "AAAAAAAA", "quantity": 12}}, "")
+	r.ServeHTTP(res, req)
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	req, res = InitHTTP(GET, rootURL+location+"/history", nil)
+	r.ServeHTTP(res, req)
+	var history []models.HistoryEntry
+	if err := json.Unmarshal(res.Body.Bytes(), &history); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := len(history), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := history[1], (models.HistoryEntry{ItemID: history[0].ItemID, OldQuantity: 10, NewQuantity: 12, Operation: models.OperationStocktake, Timestamp: history[1].Timestamp}); got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	tests := map[string]struct {
+		body     map[string]interface{}
+		code     int
+		wantFrom int
+		wantTo   int
+	}{
+		"valid": {
+			body:     map[string]interface{}{"amount": 4},
+			code:     http.StatusNoContent,
+			wantFrom: 6,
+			wantTo:   6,
+		},
+		"all stock": {
+			body:     map[string]interface{}{"amount": 10},
+			code:     http.StatusNoContent,
+			wantFrom: 0,
+			wantTo:   12,
+		},
+		"insufficient stock": {
+			body:     map[string]interface{}{"amount": 11},
+			code:     http.StatusConflict,
+			wantFrom: 10,
+			wantTo:   2,
+		},
+		"missing destination": {
+			body:     map[string]interface{}{"to": "00000000000000000000", "amount": 4},
+			code:     http.StatusNotFound,
+			wantFrom: 10,
+			wantTo:   2,
+		},
+		"same item": {
+			body:     map[string]interface{}{"to": "", "amount": 4},
+			code:     http.StatusBadRequest,
+			wantFrom: 10,
+			wantTo:   2,
+		},
+		"missing amount": {
+			body:     map[string]interface{}{},
+			code:     http.StatusBadRequest,
+			wantFrom: 10,
+			wantTo:   2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+			urls := []string{}
+			for _, bodyMap := range []map[string]interface{}{
+				{"sku": "AAAAAAAA", "name": "Part", "quantity": 10},
+				{"sku": "BBBBBBBB", "name": "Kit", "quantity": 2},
+			} {
+				req, res := InitHTTP(POST, rootURL, bodyMap)
+				r.ServeHTTP(res, req)
+				urls = append(urls, res.Header().Get("Location"))
+			}
+
+			body := map[string]interface{}{"from": urls[0][1:], "to": urls[1][1:]}
+			for k, v := range test.body {
+				body[k] = v
+			}
+			if body["to"] == "" {
+				body["to"] = body["from"]
+			}
+			req, res := InitHTTP(POST, rootURL+"/transfer", body)
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			// Check the quantities of both items
+			for i, want := range []int{test.wantFrom, test.wantTo} {
+				req, res := InitHTTP(GET, rootURL+urls[i], nil)
+				r.ServeHTTP(res, req)
+				var item models.Item
+				if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+					t.Fatal("Parse JSON Data Error")
+				}
+				if got := *item.Quantity; got != want {
+					t.Errorf("got %v; want %v", got, want)
+				}
+			}
+			if test.code != http.StatusNoContent {
+				return
+			}
+
+			// Check both legs were recorded
+			for i := range urls {
+				req, res := InitHTTP(GET, rootURL+urls[i]+"/history", nil)
+				r.ServeHTTP(res, req)
+				var history []models.HistoryEntry
+				if err := json.Unmarshal(res.Body.Bytes(), &history); err != nil {
+					t.Fatal("Parse JSON Data Error")
+				}
+				if got, want := history[len(history)-1].Operation, models.OperationTransfer; got != want {
+					t.Errorf("got %v; want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAdjustQuantityInvalid(t *testing.T) {
+	r := Setup()
+
+	// Adjust the non-existent item at /api/items/00000000000000000000
+	req, res := InitHTTP(POST, rootURL+"/00000000000000000000/adjust", map[string]interface{}{"amount": 1})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Create the item
+	req, res = InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+	r.ServeHTTP(res, req)
+	location := res.Result().Header.Values("Location")
+	if location == nil || len(location) != 1 {
+		t.Fatalf("got %v; want %v", len(location), 1)
+	}
+
+	// Adjust the item without an amount
+	req, res = InitHTTP(POST, rootURL+location[0]+"/adjust", map[string]interface{}{})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestReserveAndRelease(t *testing.T) {
+	r := Setup()
+
+	// Create the item
+	bodyMap := map[string]interface{}{
+		"sku":      "AAAAAAAA",
+		"name":     "Thing1",
+		"quantity": 5,
+	}
+
+	req, res := InitHTTP(POST, rootURL, bodyMap)
+	r.ServeHTTP(res, req)
+
+	// Check the item was created successfully
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	header := res.Result().Header
+	location := header.Values("Location")
+
+	if location == nil || len(location) != 1 {
+		t.Fatalf("got %v; want %v", len(location), 1)
+	}
+
+	steps := []struct {
+		action    string
+		amount    int
+		code      int
+		reserved  int
+		available int
+	}{
+		{action: "/reserve", amount: 3, code: http.StatusNoContent, reserved: 3, available: 2},
+		{action: "/reserve", amount: 3, code: http.StatusConflict, reserved: 3, available: 2},
+		{action: "/reserve", amount: 2, code: http.StatusNoContent, reserved: 5, available: 0},
+		{action: "/release", amount: 6, code: http.StatusConflict, reserved: 5, available: 0},
+		{action: "/release", amount: 4, code: http.StatusNoContent, reserved: 1, available: 4},
+	}
+
+	for i, step := range steps {
+		req, res = InitHTTP(POST, rootURL+location[0]+step.action, map[string]interface{}{"amount": step.amount})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, step.code; got != want {
+			t.Errorf("step %d: got %v; want %v", i, got, want)
+		}
+
+		// Get the item
+		req, res = InitHTTP(GET, rootURL+location[0], nil)
+		r.ServeHTTP(res, req)
+
+		var item models.Item
+		if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+			t.Fatal("Parse JSON Data Error")
+		}
+		if item.Reserved != step.reserved {
+			t.Errorf("step %d: expected item to have %d reserved; got %d", i, step.reserved, item.Reserved)
+		}
+		if item.Available != step.available {
+			t.Errorf("step %d: expected item to have %d available; got %d", i, step.available, item.Available)
+		}
+		if *item.Quantity != 5 {
+			t.Errorf("step %d: expected item to have quantity 5; got %d", i, *item.Quantity)
+		}
+	}
+}
+
+func TestReserveInvalid(t *testing.T) {
+	r := Setup()
+
+	// Reserve stock of the non-existent item at /api/items/00000000000000000000
+	req, res := InitHTTP(POST, rootURL+"/00000000000000000000/reserve", map[string]interface{}{"amount": 1})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Create the item
+	req, res = InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 5})
+	r.ServeHTTP(res, req)
+	location := res.Result().Header.Values("Location")
+	if location == nil || len(location) != 1 {
+		t.Fatalf("got %v; want %v", len(location), 1)
+	}
+
+	tests := map[string]map[string]interface{}{
+		"no amount":       {},
+		"zero amount":     {"amount": 0},
+		"negative amount": {"amount": -1},
+		"float amount":    {"amount": 1.5},
+	}
+
+	for name, bodyMap := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, action := range []string{"/reserve", "/release"} {
+				req, res := InitHTTP(POST, rootURL+location[0]+action, bodyMap)
+				r.ServeHTTP(res, req)
+
+				if got, want := res.Code, http.StatusBadRequest; got != want {
+					t.Errorf("%s: got %v; want %v", action, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateItemPrice(t *testing.T) {
+	r := Setup()
+
+	tests := map[string]struct {
+		bodyMap map[string]interface{}
+		want    models.Price
+	}{
+		"price": {
+			bodyMap: map[string]interface{}{
+				"sku":   "AAAAAAAA",
+				"name":  "Thing1",
+				"price": map[string]interface{}{"amount": 15.00, "currency": "USD"},
+			},
+			want: models.Price{Amount: 15.00, Currency: "USD"},
+		},
+		"legacy price_CAD": {
+			bodyMap: map[string]interface{}{
+				"sku":       "BBBBBBBB",
+				"name":      "Thing2",
+				"price_CAD": 20.00,
+			},
+			want: models.Price{Amount: 20.00, Currency: "CAD"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL, test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			// Check the item was created successfully
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			location := res.Result().Header.Values("Location")
+			if location == nil || len(location) != 1 {
+				t.Fatalf("got %v; want %v", len(location), 1)
+			}
+
+			// Get the item
+			req, res = InitHTTP(GET, rootURL+location[0], nil)
+			r.ServeHTTP(res, req)
+
+			var item map[string]interface{}
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if _, ok := item["price_CAD"]; ok {
+				t.Error("expected item not to have price_CAD")
+			}
+			price, ok := item["price"].(map[string]interface{})
+			if !ok {
+				t.Fatal("expected item to have a price")
+			}
+			if price["amount"] != test.want.Amount || price["currency"] != test.want.Currency {
+				t.Errorf("got %v; want %v", price, test.want)
+			}
+		})
+	}
+}
+
+func TestCreateItemInvalidPrice(t *testing.T) {
+	r := Setup()
+
+	tests := map[string]map[string]interface{}{
+		"unknown currency": {
+			"sku":   "AAAAAAAA",
+			"name":  "Thing1",
+			"price": map[string]interface{}{"amount": 15.00, "currency": "ZZZ"},
+		},
+		"negative amount": {
+			"sku":   "AAAAAAAA",
+			"name":  "Thing1",
+			"price": map[string]interface{}{"amount": -15.00, "currency": "USD"},
+		},
+		"price and price_CAD": {
+			"sku":       "AAAAAAAA",
+			"name":      "Thing1",
+			"price":     map[string]interface{}{"amount": 15.00, "currency": "USD"},
+			"price_CAD": 20.00,
+		},
+	}
+
+	for name, bodyMap := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL, bodyMap)
+			r.ServeHTTP(res, req)
+
+			// Check the item was rejected
+			if got, want := res.Code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemsMinValue(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	bodyMaps := []map[string]interface{}{
+		{
+			"sku":       "AAAAAAAA",
+			"name":      "Above",
+			"price_CAD": 25.00,
+			"quantity":  5,
+		},
+		{
+			"sku":       "BBBBBBBB",
+			"name":      "Below",
+			"price_CAD": 25.00,
+			"quantity":  3,
+		},
+		{
+			"sku":      "CCCCCCCC",
+			"name":     "Unpriced",
+			"quantity": 1000,
+		},
+	}
+
+	for _, bodyMap := range bodyMaps {
+		req, res := InitHTTP(POST, rootURL, bodyMap)
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	// Get the high-value items
+	req, res := InitHTTP(GET, rootURL+"?min_value=100", nil)
+	r.ServeHTTP(res, req)
+
+	var items []models.Item
+	if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if len(items) != 1 {
+		t.Fatalf("got %v; want %v", len(items), 1)
+	}
+	if items[0].SKU != "AAAAAAAA" {
+		t.Errorf(`expected item to have sku "AAAAAAAA"; got %s`, items[0].SKU)
+	}
+
+	// Attempt to filter with a malformed threshold
+	req, res = InitHTTP(GET, rootURL+"?min_value=lots", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGetItemsOrder(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	want := []models.SKU{}
+	for _, sku := range []models.SKU{"CCCCCCCC", "AAAAAAAA", "DDDDDDDD", "BBBBBBBB"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": string(sku), "quantity": 1})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		want = append(want, sku)
+	}
+
+	// Check that the items come back in the order they were added, every time
+	for i := 0; i < 5; i++ {
+		req, res := InitHTTP(GET, rootURL, nil)
+		r.ServeHTTP(res, req)
+
+		var items []models.Item
+		if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+			t.Fatal("Parse JSON Data Error")
+		}
+		got := []models.SKU{}
+		for _, item := range items {
+			got = append(got, item.SKU)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestDefaultCurrency(t *testing.T) {
+	t.Setenv("DEFAULT_CURRENCY", "usd")
+	defer func() { models.DefaultCurrency = models.LEGACY_CURRENCY }()
+	r := Setup()
+
+	// Create the items
+	bodyMaps := []map[string]interface{}{
+		{"sku": "AAAAAAAA", "name": "Dollars", "price": map[string]interface{}{"amount": 25.00, "currency": "USD"}, "quantity": 1},
+		{"sku": "BBBBBBBB", "name": "Legacy", "price_CAD": 25.00, "quantity": 1},
+		{"sku": "CCCCCCCC", "name": "Unlabelled", "price": map[string]interface{}{"amount": 5.00}, "quantity": 1},
+	}
+
+	for _, bodyMap := range bodyMaps {
+		req, res := InitHTTP(POST, rootURL, bodyMap)
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	// Only prices in the default currency match a price filter
+	req, res := InitHTTP(GET, rootURL+"?min_price=10", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	var items []models.Item
+	if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if len(items) != 1 || items[0].SKU != "AAAAAAAA" {
+		t.Errorf("got %v; want %v", items, []models.SKU{"AAAAAAAA"})
+	}
+
+	// A price without a currency is in the default currency
+	req, res = InitHTTP(GET, rootURL+"?max_price=10", nil)
+	r.ServeHTTP(res, req)
+
+	items = nil
+	if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if len(items) != 1 || items[0].Price.Currency != "USD" {
+		t.Errorf("got %v; want %v", items, []models.SKU{"CCCCCCCC"})
+	}
+
+	// The legacy price_CAD field is still accepted, in CAD
+	req, res = InitHTTP(GET, rootURL, nil)
+	r.ServeHTTP(res, req)
+
+	items = nil
+	if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	for _, item := range items {
+		if item.SKU == "BBBBBBBB" {
+			if got, want := item.Price.Currency, models.LEGACY_CURRENCY; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestEnvCurrency(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    string
+		isError bool
+	}{
+		"unset":     {"", "CAD", false},
+		"uppercase": {"EUR", "EUR", false},
+		"lowercase": {" usd ", "USD", false},
+		"unknown":   {"ZZZ", "CAD", true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("DEFAULT_CURRENCY", test.value)
+			got, err := DefaultCurrency()
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSKULengthBounds(t *testing.T) {
+	t.Setenv("SKU_MIN_LEN", "13")
+	t.Setenv("SKU_MAX_LEN", "13")
+	defer func() { models.SKUMinLen, models.SKUMaxLen = models.SKU_MIN_LEN, models.SKU_MAX_LEN }()
+	r := Setup()
+
+	tests := map[string]struct {
+		sku  string
+		code int
+	}{
+		"EAN-13":    {"4006381333931", http.StatusCreated},
+		"too short": {"400638133393", http.StatusBadRequest},
+		"too long":  {"40063813339310", http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": test.sku, "name": test.sku})
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDescriptionMaxLen(t *testing.T) {
+	t.Setenv("DESCRIPTION_MAX_LEN", "8")
+	defer func() { models.DescriptionMaxLen = models.DESCRIPTION_MAX_LEN }()
+	r := Setup()
+
+	tests := map[string]struct {
+		sku         string
+		description string
+		code        int
+	}{
+		"at limit":           {"AAAAAAAA", "abcdefgh", http.StatusCreated},
+		"at limit multibyte": {"BBBBBBBB", "àéîõüçñß", http.StatusCreated},
+		"over limit":         {"CCCCCCCC", "abcdefghi", http.StatusBadRequest},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": test.sku, "name": name, "description": test.description})
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestNameMaxLen(t *testing.T) {
+	t.Setenv("NAME_MAX_LEN", "8")
+	defer func() { models.NameMaxLen = models.NAME_MAX_LEN }()
+	r := Setup()
+
+	tests := map[string]struct {
+		sku  string
+		name string
+		code int
+	}{
+		"at limit":       {"AAAAAAAA", "abcdefgh", http.StatusCreated},
+		"past limit":     {"BBBBBBBB", "abcdefghi", http.StatusBadRequest},
+		"multibyte":      {"CCCCCCCC", "日本語の名前です", http.StatusCreated},
+		"trimmed to fit": {"DDDDDDDD", "  abcdefg  ", http.StatusCreated},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": test.sku, "name": test.name})
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if test.code != http.StatusBadRequest {
+				return
+			}
+			if got, want := res.Header().Get("X-Error-Field"), "name"; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEnvSKULengths(t *testing.T) {
+	tests := map[string]struct {
+		min, max         string
+		wantMin, wantMax int
+	}{
+		"unset":                   {"", "", models.SKU_MIN_LEN, models.SKU_MAX_LEN},
+		"both set":                {"13", "13", 13, 13},
+		"max only":                {"", "20", models.SKU_MIN_LEN, 20},
+		"not a number":            {"four", "", models.SKU_MIN_LEN, models.SKU_MAX_LEN},
+		"min exceeds max":         {"8", "6", models.SKU_MIN_LEN, models.SKU_MAX_LEN},
+		"min exceeds default max": {"13", "", models.SKU_MIN_LEN, models.SKU_MAX_LEN},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("SKU_MIN_LEN", test.min)
+			t.Setenv("SKU_MAX_LEN", test.max)
+			min, max := envSKULengths("SKU_MIN_LEN", "SKU_MAX_LEN")
+			if min != test.wantMin || max != test.wantMax {
+				t.Errorf("got %v, %v; want %v, %v", min, max, test.wantMin, test.wantMax)
+			}
+		})
+	}
+}
+
+func TestEnvBasePath(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		want  string
+	}{
+		"unset":              {"", DEFAULT_BASE_PATH},
+		"versioned":          {"/api/v1", "/api/v1"},
+		"no leading slash":   {"api/v1", "/api/v1"},
+		"trailing slash":     {"/api/v1/", "/api/v1"},
+		"root":               {"/", ""},
+		"route variable":     {"/api/{version}", DEFAULT_BASE_PATH},
+		"query string":       {"/api?v=1", DEFAULT_BASE_PATH},
+		"surrounding spaces": {" /api/v2 ", "/api/v2"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("BASE_PATH", test.value)
+			if got, want := envBasePath("BASE_PATH"), test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	tests := map[string]struct {
+		method string
+		url    string
+		key    string
+		code   int
+	}{
+		"create under base path":        {method: POST, url: "/api/v1/items", key: "rw", code: http.StatusCreated},
+		"list under base path":          {method: GET, url: "/api/v1/items", key: "rw", code: http.StatusOK},
+		"list under default path":       {method: GET, url: "/api/items", key: "rw", code: http.StatusNotFound},
+		"read-only batch get":           {method: POST, url: "/api/v1/items/batch-get", key: "ro", code: http.StatusOK},
+		"read-only create":              {method: POST, url: "/api/v1/items", key: "ro", code: http.StatusForbidden},
+		"operational route at the root": {method: GET, url: "/version", key: "rw", code: http.StatusOK},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{
+				db:      db.NewMockDB(),
+				config:  Config{APIKeys: []string{"rw"}, ReadOnlyAPIKeys: []string{"ro"}, BasePath: "/api/v1"},
+				metrics: newMetrics(),
+			}
+			t.Setenv("BASE_PATH", "/api/v1")
+			r := Routes(s)
+
+			body := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}
+			if strings.HasSuffix(test.url, "/batch-get") {
+				body = map[string]interface{}{"ids": []string{"00000000000000000000"}}
+			}
+			req, res := InitAdminHTTP(test.method, test.url, body, test.key)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAdminPath(t *testing.T) {
+	tests := map[string]struct {
+		basePath string
+		url      string
+		key      string
+		code     int
+	}{
+		"admin endpoint with admin key":        {basePath: "/api", url: "/api/admin/sku-normalization/preview", key: "secret", code: http.StatusOK},
+		"admin endpoint with api key":          {basePath: "/api", url: "/api/admin/sku-normalization/preview", key: "rw", code: http.StatusForbidden},
+		"base path containing admin, no key":   {basePath: "/admin/v1", url: "/admin/v1/items", key: "", code: http.StatusUnauthorized},
+		"base path containing admin, api key":  {basePath: "/admin/v1", url: "/admin/v1/items", key: "rw", code: http.StatusOK},
+		"admin endpoint under admin base path": {basePath: "/admin/v1", url: "/admin/v1/admin/sku-normalization/preview", key: "secret", code: http.StatusOK},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &Server{
+				db:      db.NewMockDB(),
+				config:  Config{APIKeys: []string{"rw"}, AdminAPIKey: "secret", BasePath: test.basePath},
+				metrics: newMetrics(),
+			}
+			t.Setenv("BASE_PATH", test.basePath)
+			r := Routes(s)
+
+			req, res := InitAdminHTTP(GET, test.url, nil, test.key)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemsPriceRange(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	bodyMaps := []map[string]interface{}{
+		{"sku": "AAAAAAAA", "name": "Cheap", "price_CAD": 5.00, "quantity": 1},
+		{"sku": "BBBBBBBB", "name": "Mid", "price_CAD": 25.00, "quantity": 1},
+		{"sku": "CCCCCCCC", "name": "Dear", "price_CAD": 75.00, "quantity": 1},
+		{"sku": "DDDDDDDD", "name": "Unpriced", "quantity": 1},
+	}
+
+	for _, bodyMap := range bodyMaps {
+		req, res := InitHTTP(POST, rootURL, bodyMap)
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	tests := map[string]struct {
+		query string
+		skus  []models.SKU
+	}{
+		"min and max":                {"?min_price=10&max_price=50", []models.SKU{"BBBBBBBB"}},
+		"inclusive bounds":           {"?min_price=25&max_price=25", []models.SKU{"BBBBBBBB"}},
+		"min only excludes unpriced": {"?min_price=10", []models.SKU{"BBBBBBBB", "CCCCCCCC"}},
+		"zero min excludes unpriced": {"?min_price=0", []models.SKU{"AAAAAAAA", "BBBBBBBB", "CCCCCCCC"}},
+		"max only keeps unpriced":    {"?max_price=50", []models.SKU{"AAAAAAAA", "BBBBBBBB", "DDDDDDDD"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, rootURL+test.query, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			var items []models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			skus := []models.SKU{}
+			for _, item := range items {
+				skus = append(skus, item.SKU)
+			}
+			sort.Slice(skus, func(i, j int) bool { return skus[i] < skus[j] })
+			if !reflect.DeepEqual(skus, test.skus) {
+				t.Errorf("got %v; want %v", skus, test.skus)
+			}
+		})
+	}
+}
+
+func TestGetItemsInvalidPriceRange(t *testing.T) {
+	r := Setup()
+
+	for _, query := range []string{
+		"?min_price=cheap",
+		"?max_price=-1",
+		"?min_price=NaN",
+		"?max_price=Inf",
+		"?min_price=50&max_price=10",
+	} {
+		t.Run(query, func(t *testing.T) {
+			req, res := InitHTTP(GET, rootURL+query, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemsInStock(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	locations := map[string]string{}
+	for sku, qty := range map[string]int{"AAAAAAAA": 5, "BBBBBBBB": 0, "CCCCCCCC": 2} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing", "quantity": qty})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		locations[sku] = res.Result().Header.Get("Location")
+	}
+
+	// Reserve all the stock of the third item
+	req, res := InitHTTP(POST, rootURL+locations["CCCCCCCC"]+"/reserve", map[string]interface{}{"amount": 2})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	tests := map[string]struct {
+		query string
+		code  int
+		skus  []models.SKU
+	}{
+		"in stock":     {"?in_stock=true", http.StatusOK, []models.SKU{"AAAAAAAA"}},
+		"out of stock": {"?in_stock=false", http.StatusOK, []models.SKU{"BBBBBBBB", "CCCCCCCC"}},
+		"absent":       {"", http.StatusOK, []models.SKU{"AAAAAAAA", "BBBBBBBB", "CCCCCCCC"}},
+		"malformed":    {"?in_stock=maybe", http.StatusBadRequest, nil},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, rootURL+test.query, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.skus == nil {
+				return
+			}
+
+			var items []models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			skus := []models.SKU{}
+			for _, item := range items {
+				skus = append(skus, item.SKU)
+			}
+			sort.Slice(skus, func(i, j int) bool { return skus[i] < skus[j] })
+			if !reflect.DeepEqual(skus, test.skus) {
+				t.Errorf("got %v; want %v", skus, test.skus)
+			}
+		})
+	}
+}
+
+func TestGetItemsDeleted(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	locations := map[string]string{}
+	for _, sku := range []string{"AAAAAAAA", "BBBBBBBB"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing", "quantity": 1})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		locations[sku] = res.Result().Header.Get("Location")
+	}
+
+	// Delete the second item
+	req, res := InitHTTP(DELETE, rootURL+locations["BBBBBBBB"], nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	tests := map[string]struct {
+		url     string
+		code    int
+		skus    []models.SKU
+		deleted []models.SKU
+	}{
+		"excluded by default":         {rootURL, http.StatusOK, []models.SKU{"AAAAAAAA"}, []models.SKU{}},
+		"not included":                {rootURL + "?include_deleted=false", http.StatusOK, []models.SKU{"AAAAAAAA"}, []models.SKU{}},
+		"included":                    {rootURL + "?include_deleted=true", http.StatusOK, []models.SKU{"AAAAAAAA", "BBBBBBBB"}, []models.SKU{"BBBBBBBB"}},
+		"malformed":                   {rootURL + "?include_deleted=maybe", http.StatusBadRequest, nil, nil},
+		"deleted only":                {rootURL + "/deleted", http.StatusOK, []models.SKU{"BBBBBBBB"}, []models.SKU{"BBBBBBBB"}},
+		"deleted only, included":      {rootURL + "/deleted?include_deleted=true", http.StatusOK, []models.SKU{"BBBBBBBB"}, []models.SKU{"BBBBBBBB"}},
+		"deleted only, filtered":      {rootURL + "/deleted?in_stock=false", http.StatusOK, []models.SKU{}, []models.SKU{}},
+		"deleted after":               {rootURL + "/deleted?deleted_after=2000-01-01T00:00:00Z", http.StatusOK, []models.SKU{"BBBBBBBB"}, []models.SKU{"BBBBBBBB"}},
+		"deleted before":              {rootURL + "/deleted?deleted_before=2000-01-01T00:00:00Z", http.StatusOK, []models.SKU{}, []models.SKU{}},
+		"deleted, malformed":          {rootURL + "/deleted?deleted_after=2000-01-01", http.StatusBadRequest, nil, nil},
+		"deleted, paged":              {rootURL + "/deleted?limit=1&offset=1", http.StatusOK, []models.SKU{}, []models.SKU{}},
+		"deleted after, included":     {rootURL + "?include_deleted=true&deleted_after=2000-01-01T00:00:00Z", http.StatusOK, []models.SKU{"BBBBBBBB"}, []models.SKU{"BBBBBBBB"}},
+		"deleted after, not included": {rootURL + "?deleted_after=2000-01-01T00:00:00Z", http.StatusOK, []models.SKU{}, []models.SKU{}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, test.url, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.skus == nil {
+				return
+			}
+
+			var items []models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			skus, deleted := []models.SKU{}, []models.SKU{}
+			for _, item := range items {
+				skus = append(skus, item.SKU)
+				if item.DeletedAt != nil {
+					deleted = append(deleted, item.SKU)
+				}
+			}
+			sort.Slice(skus, func(i, j int) bool { return skus[i] < skus[j] })
+			if !reflect.DeepEqual(skus, test.skus) {
+				t.Errorf("got %v; want %v", skus, test.skus)
+			}
+			if !reflect.DeepEqual(deleted, test.deleted) {
+				t.Errorf("got %v; want %v", deleted, test.deleted)
+			}
+		})
+	}
+
+	// Deleting the item again finds nothing to delete
+	req, res = InitHTTP(DELETE, rootURL+locations["BBBBBBBB"], nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := map[string]struct {
+		config Config
+		url    string
+		key    string
+		code   int
+	}{
+		"analyze": {
+			config: Config{AdminAPIKey: "secret", EnableMaintenance: true},
+			url:    "/api/admin/maintenance/analyze",
+			key:    "secret",
+			code:   http.StatusNoContent,
+		},
+		"vacuum": {
+			config: Config{AdminAPIKey: "secret", EnableMaintenance: true},
+			url:    "/api/admin/maintenance/analyze?vacuum=true",
+			key:    "secret",
+			code:   http.StatusNoContent,
+		},
+		"malformed vacuum": {
+			config: Config{AdminAPIKey: "secret", EnableMaintenance: true},
+			url:    "/api/admin/maintenance/analyze?vacuum=maybe",
+			key:    "secret",
+			code:   http.StatusBadRequest,
+		},
+		"missing key": {
+			config: Config{AdminAPIKey: "secret", EnableMaintenance: true},
+			url:    "/api/admin/maintenance/analyze",
+			key:    "",
+			code:   http.StatusUnauthorized,
+		},
+		"wrong key": {
+			config: Config{AdminAPIKey: "secret", EnableMaintenance: true},
+			url:    "/api/admin/maintenance/analyze",
+			key:    "guess",
+			code:   http.StatusForbidden,
+		},
+		"maintenance disabled": {
+			config: Config{AdminAPIKey: "secret"},
+			url:    "/api/admin/maintenance/analyze",
+			key:    "secret",
+			code:   http.StatusForbidden,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := SetupWithConfig(test.config)
+
+			req, res := InitAdminHTTP(POST, test.url, nil, test.key)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestItemTags(t *testing.T) {
+	r := Setup()
+
+	// STEP 1
+	// Create the items
+	bodyMaps := []map[string]interface{}{
+		{
+			"sku":  "AAAAAAAA",
+			"name": "Headphones",
+			"tags": []string{"electronics", "audio"},
+		},
+		{
+			"sku":  "BBBBBBBB",
+			"name": "Television",
+			"tags": []string{"electronics", "video"},
+		},
+		{
+			"sku":  "CCCCCCCC",
+			"name": "Chair",
+		},
+	}
+
+	locations := []string{}
+	for _, bodyMap := range bodyMaps {
+		req, res := InitHTTP(POST, rootURL, bodyMap)
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		locations = append(locations, res.Result().Header.Get("Location"))
+	}
+
+	// STEP 2
+	// Filter the items by tags
+	tests := map[string]struct {
+		query string
+		want  []models.SKU
+	}{
+		"one tag":       {query: "?tag=electronics", want: []models.SKU{"AAAAAAAA", "BBBBBBBB"}},
+		"two tags":      {query: "?tag=electronics&tag=audio", want: []models.SKU{"AAAAAAAA"}},
+		"disjoint tags": {query: "?tag=audio&tag=video", want: []models.SKU{}},
+		"unknown tag":   {query: "?tag=furniture", want: []models.SKU{}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, rootURL+test.query, nil)
+			r.ServeHTTP(res, req)
+
+			var items []models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			skus := []models.SKU{}
+			for _, item := range items {
+				skus = append(skus, item.SKU)
+			}
+			sort.Slice(skus, func(i, j int) bool { return skus[i] < skus[j] })
+			if !reflect.DeepEqual(skus, test.want) {
+				t.Errorf("got %v; want %v", skus, test.want)
+			}
+		})
+	}
+
+	// STEP 3
+	// Retag the television
+	bodyMap := map[string]interface{}{
+		"sku":  "BBBBBBBB",
+		"name": "Television",
+		"tags": []string{"video"},
+	}
+	req, res := InitHTTP(PUT, rootURL+locations[1], bodyMap)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// STEP 4
+	// List the tags
+	req, res = InitHTTP(GET, rootURL+"/tags", nil)
+	r.ServeHTTP(res, req)
+
+	var tags []models.TagCount
+	if err := json.Unmarshal(res.Body.Bytes(), &tags); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	want := []models.TagCount{
+		{Tag: "audio", Count: 1},
+		{Tag: "electronics", Count: 1},
+		{Tag: "video", Count: 1},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("got %v; want %v", tags, want)
+	}
+}
+
+func TestItemSupplier(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	bodyMaps := []map[string]interface{}{
+		{"sku": "AAAAAAAA", "name": "Headphones", "supplier": map[string]interface{}{"name": "Acme", "sku": "AC-1001"}},
+		{"sku": "BBBBBBBB", "name": "Television", "supplier": map[string]interface{}{"name": "ACME"}},
+		{"sku": "CCCCCCCC", "name": "Chair", "supplier": map[string]interface{}{"name": "Globex"}},
+		{"sku": "DDDDDDDD", "name": "Table"},
+	}
+	for _, bodyMap := range bodyMaps {
+		req, res := InitHTTP(POST, rootURL, bodyMap)
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	// Filter the items by supplier
+	tests := map[string]struct {
+		query string
+		want  []models.SKU
+	}{
+		"supplier":         {query: "?supplier=Acme", want: []models.SKU{"AAAAAAAA", "BBBBBBBB"}},
+		"ignores case":     {query: "?supplier=globex", want: []models.SKU{"CCCCCCCC"}},
+		"unknown supplier": {query: "?supplier=Initech", want: []models.SKU{}},
+		"blank supplier":   {query: "?supplier=+", want: []models.SKU{"AAAAAAAA", "BBBBBBBB", "CCCCCCCC", "DDDDDDDD"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, rootURL+test.query, nil)
+			r.ServeHTTP(res, req)
+
+			var items []models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			skus := []models.SKU{}
+			for _, item := range items {
+				skus = append(skus, item.SKU)
+			}
+			sort.Slice(skus, func(i, j int) bool { return skus[i] < skus[j] })
+			if !reflect.DeepEqual(skus, test.want) {
+				t.Errorf("got %v; want %v", skus, test.want)
+			}
+		})
+	}
+
+	// Supplier names are required
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "EEEEEEEE", "name": "Lamp", "supplier": map[string]interface{}{"sku": "AC-1002"}})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Header().Get("X-Error-Field"), "supplier"; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestCreateItemInvalidTags(t *testing.T) {
+	r := Setup()
+
+	tests := map[string]map[string]interface{}{
+		"empty tag": {
+			"sku":  "AAAAAAAA",
+			"name": "Thing1",
+			"tags": []string{""},
+		},
+		"long tag": {
+			"sku":  "AAAAAAAA",
+			"name": "Thing1",
+			"tags": []string{"abcdefghijklmnopqrstuvwxyz0123456"},
+		},
+		"non-string tag": {
+			"sku":  "AAAAAAAA",
+			"name": "Thing1",
+			"tags": []interface{}{1},
+		},
+	}
+
+	for name, bodyMap := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL, bodyMap)
+			r.ServeHTTP(res, req)
+
+			// Check the item was rejected
+			if got, want := res.Code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUpsertItem(t *testing.T) {
+	r := Setup()
+	url := rootURL + "/00000000000000000001"
+
+	// STEP 1
+	// Upsert the non-existent item
+	bodyMap := map[string]interface{}{
+		"sku":      "AAAAAAAA",
+		"name":     "Thing1",
+		"quantity": 3,
+	}
+
+	req, res := InitHTTP(PUT, url, bodyMap)
+	req.Header.Set("X-Upsert", "true")
+	r.ServeHTTP(res, req)
+
+	// Check the item was created with the requested ID
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Result().Header.Get("Location"), "/00000000000000000001"; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// STEP 2
+	// Upsert the now-existing item
+	bodyMap["name"] = "ThingOne"
+	req, res = InitHTTP(PUT, url, bodyMap)
+	req.Header.Set("X-Upsert", "true")
+	r.ServeHTTP(res, req)
+
+	// Check the item was updated
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Get the item
+	req, res = InitHTTP(GET, url, nil)
+	r.ServeHTTP(res, req)
+
+	var item models.Item
+	if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if item.Name != "ThingOne" {
+		t.Errorf(`expected item to have name "ThingOne"; got %s`, item.Name)
+	}
+	if *item.Quantity != 3 {
+		t.Errorf(`expected item to have quantity 3; got %d`, *item.Quantity)
+	}
+}
+
+func TestUpsertItemInvalid(t *testing.T) {
+	r := Setup()
+
+	// Create the item
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	tests := map[string]struct {
+		url     string
+		bodyMap map[string]interface{}
+		code    int
+	}{
+		"malformed id": {
+			url:     rootURL + "/not-a-real-ID",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"},
+			code:    http.StatusBadRequest,
+		},
+		"duplicate sku": {
+			url:     rootURL + "/00000000000000000002",
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing2"},
+			code:    http.StatusConflict,
+		},
+		"invalid item": {
+			url:     rootURL + "/00000000000000000002",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB"},
+			code:    http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(PUT, test.url, test.bodyMap)
+			req.Header.Set("X-Upsert", "true")
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemFields(t *testing.T) {
+	r := Setup()
+
+	// Create the item
+	bodyMap := map[string]interface{}{
+		"sku":         "AAAAAAAA",
+		"name":        "Thing1",
+		"description": "The first thing",
+		"price":       map[string]interface{}{"amount": 10.5, "currency": "CAD"},
+		"cost_CAD":    6.5,
+		"quantity":    3,
+	}
+	req, res := InitHTTP(POST, rootURL, bodyMap)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	url := rootURL + res.Result().Header.Get("Location")
+
+	tests := map[string]struct {
+		fields string
+		want   map[string]interface{}
+	}{
+		"subset": {
+			fields: "name,price",
+			want: map[string]interface{}{
+				"name":  "Thing1",
+				"price": map[string]interface{}{"amount": 10.5, "currency": "CAD"},
+			},
+		},
+		"computed field": {
+			fields: "available",
+			want:   map[string]interface{}{"available": 3.0},
+		},
+		"computed margin": {
+			fields: "margin",
+			want:   map[string]interface{}{"margin": map[string]interface{}{"amount_CAD": 4.0, "percent": 38.1}},
+		},
+		"absent optional field": {
+			fields: "sku,tags",
+			want:   map[string]interface{}{"sku": "AAAAAAAA"},
+		},
+		"blank and repeated fields": {
+			fields: " quantity,,quantity ",
+			want:   map[string]interface{}{"quantity": 3.0},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, url+"?fields="+test.fields, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetItemMargin(t *testing.T) {
+	tests := map[string]struct {
+		price map[string]interface{}
+		cost  interface{}
+		want  *models.UnitMargin
+	}{
+		"price and cost": {
+			price: map[string]interface{}{"amount": 20.0, "currency": "CAD"},
+			cost:  15.0,
+			want:  &models.UnitMargin{AmountInCAD: 5, Percent: percent(25)},
+		},
+		"negative margin": {
+			price: map[string]interface{}{"amount": 10.0, "currency": "CAD"},
+			cost:  12.5,
+			want:  &models.UnitMargin{AmountInCAD: -2.5, Percent: percent(-25)},
+		},
+		"free item": {
+			price: map[string]interface{}{"amount": 0.0, "currency": "CAD"},
+			cost:  2.0,
+			want:  &models.UnitMargin{AmountInCAD: -2},
+		},
+		"no cost": {
+			price: map[string]interface{}{"amount": 20.0, "currency": "CAD"},
+			want:  nil,
+		},
+		"no price": {
+			cost: 15.0,
+			want: nil,
+		},
+		"price in another currency": {
+			price: map[string]interface{}{"amount": 20.0, "currency": "USD"},
+			cost:  15.0,
+			want:  nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			body := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}
+			if test.price != nil {
+				body["price"] = test.price
+			}
+			if test.cost != nil {
+				body["cost_CAD"] = test.cost
+			}
+			req, res := InitHTTP(POST, rootURL, body)
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			req, res = InitHTTP(GET, rootURL+res.Result().Header.Get("Location"), nil)
+			r.ServeHTTP(res, req)
+			var item models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if !reflect.DeepEqual(item.UnitMargin, test.want) {
+				t.Errorf("got %v; want %v", item.UnitMargin, test.want)
+			}
+		})
+	}
+}
+
+func percent(p float64) *float64 {
+	return &p
+}
+
+func TestGetItemFieldsInvalid(t *testing.T) {
+	r := Setup()
+
+	// Create the item
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	url := rootURL + res.Result().Header.Get("Location")
+
+	tests := map[string]struct {
+		url  string
+		code int
+	}{
+		"unknown field": {
+			url:  url + "?fields=name,colour",
+			code: http.StatusBadRequest,
+		},
+		"internal field": {
+			url:  url + "?fields=date_added",
+			code: http.StatusBadRequest,
+		},
+		"not found": {
+			url:  rootURL + "/not-a-real-ID?fields=name",
+			code: http.StatusNotFound,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, test.url, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestItemHistories(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	locations := []string{}
+	for _, sku := range []string{"AAAAAAAA", "BBBBBBBB"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing", "quantity": 5})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		locations = append(locations, res.Result().Header.Get("Location"))
+	}
+	idA := models.ID(locations[0][1:])
+	idB := models.ID(locations[1][1:])
+
+	// Adjust the first item
+	req, res := InitHTTP(POST, rootURL+locations[0]+"/adjust", map[string]interface{}{"amount": -2})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Get the histories, including an item with none
+	body := map[string]interface{}{"ids": []models.ID{idA, idB, "00000000000000000000"}}
+	req, res = InitHTTP(POST, rootURL+"/history/batch", body)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var histories map[models.ID][]models.HistoryEntry
+	if err := json.Unmarshal(res.Body.Bytes(), &histories); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+
+	if got, want := len(histories), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := len(histories[idA]), 2; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := len(histories[idB]), 1; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if _, ok := histories["00000000000000000000"]; ok {
+		t.Errorf("expected item without history to be omitted")
+	}
+	for id, history := range histories {
+		for _, entry := range history {
+			if entry.ItemID != id {
+				t.Errorf("got %v; want %v", entry.ItemID, id)
+			}
+		}
+	}
+}
+
+func TestItemHistoriesInvalid(t *testing.T) {
+	r := Setup()
+
+	tooMany := make([]models.ID, models.BATCH_MAX_SIZE+1)
+	for i := range tooMany {
+		tooMany[i] = models.NewID()
+	}
+
+	tests := map[string]struct {
+		bodyMap map[string]interface{}
+		code    int
+	}{
+		"missing ids": {
+			bodyMap: map[string]interface{}{},
+			code:    http.StatusBadRequest,
+		},
+		"empty ids": {
+			bodyMap: map[string]interface{}{"ids": []models.ID{}},
+			code:    http.StatusBadRequest,
+		},
+		"too many ids": {
+			bodyMap: map[string]interface{}{"ids": tooMany},
+			code:    http.StatusBadRequest,
+		},
+		"malformed ids": {
+			bodyMap: map[string]interface{}{"ids": "00000000000000000000"},
+			code:    http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL+"/history/batch", test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemsByIDs(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	locations := []string{}
+	for _, sku := range []string{"AAAAAAAA", "BBBBBBBB"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing", "quantity": 5})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		locations = append(locations, res.Result().Header.Get("Location"))
+	}
+	idA := models.ID(locations[0][1:])
+	idB := models.ID(locations[1][1:])
+	missing := models.ID("00000000000000000000")
+
+	// Get the items, out of order, with a duplicate and a missing item
+	body := map[string]interface{}{"ids": []models.ID{idB, missing, idA, idB}}
+	req, res := InitHTTP(POST, rootURL+"/batch-get", body)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var result models.ItemBatchResult
+	if err := json.Unmarshal(res.Body.Bytes(), &result); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+
+	if got, want := len(result.Items), 2; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := result.Items[0].ID, idB; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := result.Items[1].ID, idA; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := result.Items[0].Available, 5; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := result.Missing, []models.ID{missing}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGetItemsByIDsInvalid(t *testing.T) {
+	r := Setup()
+
+	tooMany := make([]models.ID, models.BATCH_MAX_SIZE+1)
+	for i := range tooMany {
+		tooMany[i] = models.NewID()
+	}
+
+	tests := map[string]struct {
+		bodyMap map[string]interface{}
+		code    int
+	}{
+		"missing ids": {
+			bodyMap: map[string]interface{}{},
+			code:    http.StatusBadRequest,
+		},
+		"empty ids": {
+			bodyMap: map[string]interface{}{"ids": []models.ID{}},
+			code:    http.StatusBadRequest,
+		},
+		"too many ids": {
+			bodyMap: map[string]interface{}{"ids": tooMany},
+			code:    http.StatusBadRequest,
+		},
+		"malformed ids": {
+			bodyMap: map[string]interface{}{"ids": "00000000000000000000"},
+			code:    http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(POST, rootURL+"/batch-get", test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemETag(t *testing.T) {
+	r := Setup()
+
+	// STEP 1
+	// Create the item
+	bodyMap := map[string]interface{}{
+		"sku":  "AAAAAAAA",
+		"name": "Thing1",
+	}
+	req, res := InitHTTP(POST, rootURL, bodyMap)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	url := rootURL + res.Result().Header.Get("Location")
+
+	// STEP 2
+	// Get the item and its ETag
+	req, res = InitHTTP(GET, url, nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	etag := res.Result().Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	// STEP 3
+	// Get the item again with its ETag
+	req, res = InitHTTP(GET, url, nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotModified; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Body.Len(), 0; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// A weak match among several ETags also counts
+	req, res = InitHTTP(GET, url, nil)
+	req.Header.Set("If-None-Match", `"stale", W/`+etag)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotModified; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// STEP 4
+	// Update the item
+	bodyMap["name"] = "ThingOne"
+	req, res = InitHTTP(PUT, url, bodyMap)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Get the item with its stale ETag
+	req, res = InitHTTP(GET, url, nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	etag2 := res.Result().Header.Get("ETag")
+	if etag2 == etag {
+		t.Errorf("expected ETag to change after update; got %v", etag2)
+	}
+
+	// STEP 5
+	// A projection has the same ETag as the whole item
+	req, res = InitHTTP(GET, url+"?fields=name", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Result().Header.Get("ETag"), etag2; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGetItemsETag(t *testing.T) {
+	r := Setup()
+
+	// Get the empty inventory and its ETag
+	req, res := InitHTTP(GET, rootURL, nil)
+	r.ServeHTTP(res, req)
+
+	etag := res.Result().Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	// Get the inventory again with its ETag
+	req, res = InitHTTP(GET, rootURL, nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotModified; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Body.Len(), 0; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Create an item
+	req, res = InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// The inventory has changed
+	req, res = InitHTTP(GET, rootURL, nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestCreateItemPrefer(t *testing.T) {
+	bodyMap := map[string]interface{}{
+		"sku":      "AAAAAAAA",
+		"name":     "Thing1",
+		"price":    map[string]interface{}{"amount": 10.0, "currency": "cad"},
+		"quantity": 3,
+	}
+
+	tests := map[string]struct {
+		prefer         string
+		applied        string
+		representation bool
+	}{
+		"no preference": {
+			prefer:         "",
+			applied:        "",
+			representation: true,
+		},
+		"minimal": {
+			prefer:         "return=minimal",
+			applied:        "return=minimal",
+			representation: false,
+		},
+		"representation": {
+			prefer:         "return=representation",
+			applied:        "return=representation",
+			representation: true,
+		},
+		"representation among other preferences": {
+			prefer:         "respond-async, return=representation; foo=bar",
+			applied:        "return=representation",
+			representation: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+			req, res := InitHTTP(POST, rootURL, bodyMap)
+			if test.prefer != "" {
+				req.Header.Set("Prefer", test.prefer)
+			}
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			location := res.Result().Header.Get("Location")
+			if location == "" {
+				t.Fatal("expected a Location")
+			}
+
+			if got, want := res.Result().Header.Get("Preference-Applied"), test.applied; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			if !test.representation {
+				if got, want := res.Body.Len(), 0; got != want {
+					t.Errorf("got %v; want %v", got, want)
+				}
+				return
+			}
+
+			var item models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := "/"+string(item.ID), location; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := item.Name, "Thing1"; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := *item.Price, (models.Price{Amount: 10.0, Currency: "CAD"}); got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := item.Available, 3; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRequestBodyTooLarge(t *testing.T) {
+	r := SetupWithConfig(Config{AdminAPIKey: "admin", MaxBodyBytes: 64})
+
+	tests := map[string]struct {
+		method  string
+		url     string
+		bodyMap map[string]interface{}
+		code    int
+	}{
+		"create within limit": {
+			method:  POST,
+			url:     rootURL,
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"},
+			code:    http.StatusCreated,
+		},
+		"create over limit": {
+			method:  POST,
+			url:     rootURL,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2", "description": strings.Repeat("a", 64)},
+			code:    http.StatusRequestEntityTooLarge,
+		},
+		"update over limit": {
+			method:  PUT,
+			url:     rootURL + "/00000000000000000001",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2", "description": strings.Repeat("a", 64)},
+			code:    http.StatusRequestEntityTooLarge,
+		},
+		"batch history over limit": {
+			method:  POST,
+			url:     rootURL + "/history/batch",
+			bodyMap: map[string]interface{}{"ids": []string{"00000000000000000001", "00000000000000000002", "00000000000000000003"}},
+			code:    http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(test.method, test.url, test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+
+	// Import is also limited
+	items := []map[string]interface{}{
+		{"id": "00000000000000000002", "sku": "BBBBBBBB", "name": "Thing2"},
+		{"id": "00000000000000000003", "sku": "CCCCCCCC", "name": "Thing3"},
+	}
+	req, res := InitAdminHTTP(POST, "/api/admin/import", items, "admin")
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusRequestEntityTooLarge; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGetItemsStream(t *testing.T) {
+	r := Setup()
+
+	// Create more items than are flushed at once
+	n := 2*STREAM_FLUSH_INTERVAL + 1
+	for i := 0; i < n; i++ {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": fmt.Sprintf("SKU%05d", i), "name": "Thing", "quantity": i})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	// Get the items
+	req, res := InitHTTP(GET, rootURL, nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	var items []models.Item
+	if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := len(items), n; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for _, item := range items {
+		if got, want := item.Available, *item.Quantity; got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}
+	etag := res.Result().Header.Get("ETag")
+
+	// Update an item
+	url := rootURL + "/" + string(items[0].ID)
+	req, res = InitHTTP(PUT, url, map[string]interface{}{"sku": string(items[0].SKU), "name": "Renamed"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// The items have changed
+	req, res = InitHTTP(GET, rootURL, nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// A filtered collection has its own ETag
+	req, res = InitHTTP(GET, rootURL+"?tag=electronics", nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := strings.TrimSpace(res.Body.String()), "[]"; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+// discardResponseWriter is a ResponseWriter that drops everything written to it,
+// so that benchmarks measure the memory held by a handler rather than by the response.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(code int)        {}
+
+// benchmarkDB creates a mock database holding n Items.
+func benchmarkDB(n int) db.DB {
+	mock := db.NewMockDB()
+	items := make([]models.Item, n)
+	for i := range items {
+		q := i
+		items[i] = models.Item{
+			ID:          models.NewID(),
+			SKU:         models.SKU(fmt.Sprintf("SKU%07d", i)),
+			Name:        "Thing",
+			Description: "A thing that is in stock",
+			Price:       &models.Price{Amount: 9.99, Currency: "CAD"},
+			Quantity:    &q,
+		}
+	}
+	mock.LoadTestItems(items)
+	return mock
+}
+
+// BenchmarkGetItemsBuffered measures the previous implementation of GetItems,
+// which read every Item into memory before encoding them.
+func BenchmarkGetItemsBuffered(b *testing.B) {
+	mock := benchmarkDB(10000)
+	w := &discardResponseWriter{header: http.Header{}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		items, code, _ := mock.GetItems(context.Background(), &models.Filter{})
+		for i := range items {
+			items[i].ComputeAvailable()
+		}
+		body, _ := json.Marshal(items)
+		w.WriteHeader(code)
+		w.Write(body)
+	}
+}
+
+// BenchmarkGetItemsStreamed measures GetItems, which streams each Item to the response.
+func BenchmarkGetItemsStreamed(b *testing.B) {
+	s := NewServer(benchmarkDB(10000))
+	w := &discardResponseWriter{header: http.Header{}}
+	req, _ := http.NewRequest(GET, rootURL, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.GetItems(w, req)
+	}
+}
+
+func TestCreateItemUnknownFields(t *testing.T) {
+	tests := map[string]struct {
+		bodyMap map[string]interface{}
+		code    int
+		msg     string
+	}{
+		"typo": {
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantty": 5},
+			code:    http.StatusBadRequest,
+			msg:     `unknown field "quantty"`,
+		},
+		"unknown nested field": {
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "price": map[string]interface{}{"amount": 5, "currency": "CAD", "tax": 1}},
+			code:    http.StatusBadRequest,
+			msg:     `unknown field "tax"`,
+		},
+		"wrong type": {
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": "five"},
+			code:    http.StatusBadRequest,
+			msg:     `/quantity: expected integer, but got string`,
+		},
+		"float quantity": {
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 5.5},
+			code:    http.StatusBadRequest,
+			msg:     `/quantity: expected integer, but got number`,
+		},
+		"wrong nested type": {
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "price": map[string]interface{}{"amount": "5", "currency": "CAD"}},
+			code:    http.StatusBadRequest,
+			msg:     `/price/amount: expected number, but got string`,
+		},
+		"missing required field": {
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA"},
+			code:    http.StatusBadRequest,
+			msg:     `/: missing properties: 'name'`,
+		},
+		"legitimate optional fields": {
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "description": "The first thing", "price_CAD": 5.0},
+			code:    http.StatusCreated,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+			req, res := InitHTTP(POST, rootURL, test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if test.msg == "" {
+				return
+			}
+
+			var msg string
+			if err := json.Unmarshal(res.Body.Bytes(), &msg); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := msg, test.msg; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUpdateItemUnknownFields(t *testing.T) {
+	r := Setup()
+
+	// Create the item
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	url := rootURL + res.Result().Header.Get("Location")
+
+	// Update it with a typo
+	req, res = InitHTTP(PUT, url, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "descripton": "Typo"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Body.String(), `"unknown field \"descripton\""`; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+// InitPatchHTTP creates a JSON Merge Patch request with the given body.
+func InitPatchHTTP(url string, body string) (*http.Request, *httptest.ResponseRecorder) {
+	req, _ := http.NewRequest(PATCH, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	res := httptest.NewRecorder()
+	return req, res
+}
+
+func TestPatchItem(t *testing.T) {
+	tests := map[string]struct {
+		patch string
+		code  int
+		want  map[string]interface{}
+	}{
+		"update name": {
+			patch: `{"name": "Thing2"}`,
+			code:  http.StatusNoContent,
+			want:  map[string]interface{}{"name": "Thing2", "description": "The first thing", "quantity": 5.0},
+		},
+		"clear description": {
+			patch: `{"description": null}`,
+			code:  http.StatusNoContent,
+			want:  map[string]interface{}{"name": "Thing1", "description": nil, "quantity": 5.0},
+		},
+		"clear price": {
+			patch: `{"price_CAD": null}`,
+			code:  http.StatusNoContent,
+			want:  map[string]interface{}{"name": "Thing1", "price": nil},
+		},
+		"replace price with price_CAD": {
+			patch: `{"price_CAD": 20}`,
+			code:  http.StatusNoContent,
+			want:  map[string]interface{}{"price": map[string]interface{}{"amount": 20.0, "currency": "CAD"}},
+		},
+		"change price currency": {
+			patch: `{"price": {"currency": "USD"}}`,
+			code:  http.StatusNoContent,
+			want:  map[string]interface{}{"price": map[string]interface{}{"amount": 15.0, "currency": "USD"}},
+		},
+		"null quantity": {
+			patch: `{"quantity": null}`,
+			code:  http.StatusBadRequest,
+		},
+		"null name": {
+			patch: `{"name": null}`,
+			code:  http.StatusBadRequest,
+		},
+		"invalid quantity": {
+			patch: `{"quantity": -1}`,
+			code:  http.StatusBadRequest,
+		},
+		"wrong type": {
+			patch: `{"quantity": "five"}`,
+			code:  http.StatusBadRequest,
+		},
+		"unknown field": {
+			patch: `{"descripton": "Typo"}`,
+			code:  http.StatusBadRequest,
+		},
+		"malformed": {
+			patch: `{"name": `,
+			code:  http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the item
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "description": "The first thing", "price_CAD": 15.00, "quantity": 5})
+			r.ServeHTTP(res, req)
+			url := rootURL + res.Result().Header.Get("Location")
+
+			// Patch it
+			req, res = InitPatchHTTP(url, test.patch)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if test.want == nil {
+				return
+			}
+
+			// Get the patched item
+			req, res = InitHTTP(GET, url, nil)
+			r.ServeHTTP(res, req)
+
+			var item map[string]interface{}
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			for field, want := range test.want {
+				if got := item[field]; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: got %v; want %v", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPatchItemNotFound(t *testing.T) {
+	r := Setup()
+
+	req, res := InitPatchHTTP(rootURL+"/01234567890123456789", `{"name": "Thing"}`)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestPatchItemUnsupportedMediaType(t *testing.T) {
+	r := Setup()
+
+	// Create the item
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+	r.ServeHTTP(res, req)
+	url := rootURL + res.Result().Header.Get("Location")
+
+	// Patch it as plain json
+	req, res = InitHTTP(PATCH, url, map[string]interface{}{"name": "Thing2"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusUnsupportedMediaType; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Header().Get("Accept-Patch"), "application/merge-patch+json"; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestUpdateItemVersion(t *testing.T) {
+	tests := map[string]struct {
+		version interface{}
+		ifMatch string
+		code    int
+		want    float64
+	}{
+		"no version": {
+			code: http.StatusNoContent,
+			want: 3,
+		},
+		"current version": {
+			version: 2,
+			code:    http.StatusNoContent,
+			want:    3,
+		},
+		"stale version": {
+			version: 1,
+			code:    http.StatusConflict,
+			want:    2,
+		},
+		"invalid version": {
+			version: -1,
+			code:    http.StatusBadRequest,
+			want:    2,
+		},
+		"current if-match": {
+			ifMatch: `"2"`,
+			code:    http.StatusNoContent,
+			want:    3,
+		},
+		"stale if-match": {
+			ifMatch: `"1"`,
+			code:    http.StatusConflict,
+			want:    2,
+		},
+		"malformed if-match": {
+			ifMatch: `"abc"`,
+			code:    http.StatusBadRequest,
+			want:    2,
+		},
+		"weak if-match": {
+			ifMatch: `W/"2"`,
+			code:    http.StatusBadRequest,
+			want:    2,
+		},
+		"any if-match": {
+			ifMatch: "*",
+			code:    http.StatusNoContent,
+			want:    3,
+		},
+		"if-match agrees with body": {
+			version: 2,
+			ifMatch: `"2"`,
+			code:    http.StatusNoContent,
+			want:    3,
+		},
+		"if-match disagrees with body": {
+			version: 1,
+			ifMatch: `"2"`,
+			code:    http.StatusBadRequest,
+			want:    2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the item at version 1 and update it to version 2
+			bodyMap := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}
+			req, res := InitHTTP(POST, rootURL, bodyMap)
+			r.ServeHTTP(res, req)
+			url := rootURL + res.Result().Header.Get("Location")
+			req, res = InitHTTP(PUT, url, bodyMap)
+			r.ServeHTTP(res, req)
+
+			// Update it with the expected version
+			bodyMap = map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing2"}
+			if test.version != nil {
+				bodyMap["version"] = test.version
+			}
+			req, res = InitHTTP(PUT, url, bodyMap)
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			// Check the item's version
+			req, res = InitHTTP(GET, url, nil)
+			r.ServeHTTP(res, req)
+			var item map[string]interface{}
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := item["version"], test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPatchItemVersion(t *testing.T) {
+	tests := map[string]struct {
+		patch   string
+		ifMatch string
+		code    int
+		want    float64
+	}{
+		"no version": {
+			patch: `{"name": "Thing2"}`,
+			code:  http.StatusNoContent,
+			want:  3,
+		},
+		"current version": {
+			patch: `{"name": "Thing2", "version": 2}`,
+			code:  http.StatusNoContent,
+			want:  3,
+		},
+		"stale version": {
+			patch: `{"name": "Thing2", "version": 1}`,
+			code:  http.StatusConflict,
+			want:  2,
+		},
+		"current if-match": {
+			patch:   `{"name": "Thing2"}`,
+			ifMatch: `"2"`,
+			code:    http.StatusNoContent,
+			want:    3,
+		},
+		"stale if-match": {
+			patch:   `{"name": "Thing2"}`,
+			ifMatch: `"1"`,
+			code:    http.StatusConflict,
+			want:    2,
+		},
+		"any if-match": {
+			patch:   `{"name": "Thing2"}`,
+			ifMatch: "*",
+			code:    http.StatusNoContent,
+			want:    3,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the item at version 1 and update it to version 2
+			bodyMap := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}
+			req, res := InitHTTP(POST, rootURL, bodyMap)
+			r.ServeHTTP(res, req)
+			url := rootURL + res.Result().Header.Get("Location")
+			req, res = InitHTTP(PUT, url, bodyMap)
+			r.ServeHTTP(res, req)
+
+			// Patch it with the expected version
+			req, res = InitPatchHTTP(url, test.patch)
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			// Check the item's version
+			req, res = InitHTTP(GET, url, nil)
+			r.ServeHTTP(res, req)
+			var item map[string]interface{}
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := item["version"], test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUpdateItemIfMatchETag(t *testing.T) {
+	r := Setup()
+	bodyMap := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}
+	req, res := InitHTTP(POST, rootURL, bodyMap)
+	r.ServeHTTP(res, req)
+	url := rootURL + res.Result().Header.Get("Location")
+
+	// The ETag from reading the item is accepted by every conditional write
+	for _, method := range []string{PUT, PATCH, DELETE} {
+		req, res = InitHTTP(GET, url, nil)
+		r.ServeHTTP(res, req)
+		etag := res.Result().Header.Get("ETag")
+
+		if method == PATCH {
+			req, res = InitPatchHTTP(url, `{"name": "Thing3"}`)
+		} else {
+			bodyMap["name"] = "Thing2"
+			req, res = InitHTTP(method, url, bodyMap)
+		}
+		req.Header.Set("If-Match", etag)
+		r.ServeHTTP(res, req)
+		if got, want := res.Code, http.StatusNoContent; got != want {
+			t.Errorf("%v: got %v; want %v", method, got, want)
+		}
+	}
+}
+
+func TestUpdateItemIfMatchAnyMissing(t *testing.T) {
+	url := rootURL + "/00000000000000000000"
+	tests := map[string]func() (*http.Request, *httptest.ResponseRecorder){
+		"update": func() (*http.Request, *httptest.ResponseRecorder) {
+			return InitHTTP(PUT, url, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+		},
+		"upsert": func() (*http.Request, *httptest.ResponseRecorder) {
+			req, res := InitHTTP(PUT, url, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+			req.Header.Set("X-Upsert", "true")
+			return req, res
+		},
+		"patch": func() (*http.Request, *httptest.ResponseRecorder) {
+			return InitPatchHTTP(url, `{"name": "Thing1"}`)
+		},
+	}
+
+	for name, init := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+			req, res := init()
+			req.Header.Set("If-Match", "*")
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, http.StatusPreconditionFailed; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			// The item is not created
+			req, res = InitHTTP(GET, url, nil)
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, http.StatusNotFound; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEnforceReservedStock(t *testing.T) {
+	defer func() { models.EnforceReservedStock = false }()
+
+	tests := map[string]struct {
+		method  string
+		path    string
+		bodyMap map[string]interface{}
+		code    int
+	}{
+		"update below reserved": {
+			method:  PUT,
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 2},
+			code:    http.StatusConflict,
+		},
+		"update to reserved": {
+			method:  PUT,
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 3},
+			code:    http.StatusNoContent,
+		},
+		"adjust below reserved": {
+			method:  POST,
+			path:    "/adjust",
+			bodyMap: map[string]interface{}{"amount": -3},
+			code:    http.StatusConflict,
+		},
+		"adjust to reserved": {
+			method:  POST,
+			path:    "/adjust",
+			bodyMap: map[string]interface{}{"amount": -2},
+			code:    http.StatusNoContent,
+		},
+		"reserve above quantity": {
+			method:  POST,
+			path:    "/reserve",
+			bodyMap: map[string]interface{}{"amount": 3},
+			code:    http.StatusConflict,
+		},
+		"release above reserved": {
+			method:  POST,
+			path:    "/release",
+			bodyMap: map[string]interface{}{"amount": 4},
+			code:    http.StatusConflict,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup configures the Server from the environment
+			r := Setup()
+			models.EnforceReservedStock = true
+
+			// Create the item with 3 of 5 units reserved
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 5})
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			url := rootURL + res.Result().Header.Get("Location")
+
+			req, res = InitHTTP(POST, url+"/reserve", map[string]interface{}{"amount": 3})
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusNoContent; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			// Change the stock
+			req, res = InitHTTP(test.method, url+test.path, test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			// Available stock is never negative
+			req, res = InitHTTP(GET, url, nil)
+			r.ServeHTTP(res, req)
+
+			var item models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if item.Available < 0 {
+				t.Errorf("expected available stock to be non-negative; got %d", item.Available)
+			}
+		})
+	}
+}
+
+func TestReservedStockNotEnforced(t *testing.T) {
+	r := Setup()
+
+	// Create the item with 3 of 5 units reserved
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 5})
+	r.ServeHTTP(res, req)
+	url := rootURL + res.Result().Header.Get("Location")
+
+	req, res = InitHTTP(POST, url+"/reserve", map[string]interface{}{"amount": 3})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// The quantity may be corrected below the reservations
+	req, res = InitHTTP(POST, url+"/adjust", map[string]interface{}{"amount": -4})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGetItemsDateRange(t *testing.T) {
+	r := Setup()
+
+	// Create two items, both added on Jan 1, 2000 by the mock database
+	locations := []string{}
+	for _, sku := range []string{"AAAAAAAA", "BBBBBBBB"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing"})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		locations = append(locations, res.Result().Header.Get("Location"))
+	}
+
+	// Update the second item, which the mock database dates a day later
+	req, res := InitHTTP(PUT, rootURL+locations[1], map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	tests := map[string]struct {
+		query string
+		want  []models.SKU
+	}{
+		"added after": {
+			query: "?added_after=2000-01-01T00:00:00Z",
+			want:  []models.SKU{"AAAAAAAA", "BBBBBBBB"},
+		},
+		"added before": {
+			query: "?added_before=2000-01-01T00:00:00Z",
+			want:  []models.SKU{},
+		},
+		"updated after": {
+			query: "?updated_after=2000-01-01T12:00:00Z",
+			want:  []models.SKU{"BBBBBBBB"},
+		},
+		"updated before": {
+			query: "?updated_before=2000-01-01T12:00:00Z",
+			want:  []models.SKU{"AAAAAAAA"},
+		},
+		"updated within window": {
+			query: "?updated_after=2000-01-01T12:00:00Z&updated_before=2000-01-03T00:00:00Z",
+			want:  []models.SKU{"BBBBBBBB"},
+		},
+		"time zone offset": {
+			query: "?updated_after=2000-01-01T07:00:00-05:00",
+			want:  []models.SKU{"BBBBBBBB"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, rootURL+test.query, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			var items []models.Item
+			if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			got := []models.SKU{}
+			for _, item := range items {
+				got = append(got, item.SKU)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetItemsInvalidDateRange(t *testing.T) {
+	r := Setup()
+
+	for _, query := range []string{
+		"?added_after=yesterday",
+		"?added_before=2000-01-01",
+		"?updated_after=2000-01-01T00:00:00",
+		"?updated_before=1644000000",
+	} {
+		t.Run(query, func(t *testing.T) {
+			req, res := InitHTTP(GET, rootURL+query, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestIncrementAndDecrement(t *testing.T) {
+	r := Setup()
+
+	// Create the item
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 2})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	url := rootURL + res.Result().Header.Get("Location")
+
+	steps := []struct {
+		path     string
+		code     int
+		quantity int
+	}{
+		{"/increment", http.StatusNoContent, 3},
+		{"/increment?by=5", http.StatusNoContent, 8},
+		{"/decrement", http.StatusNoContent, 7},
+		{"/decrement?by=7", http.StatusNoContent, 0},
+		{"/decrement", http.StatusConflict, 0},
+		{"/increment?by=0", http.StatusBadRequest, 0},
+		{"/decrement?by=-1", http.StatusBadRequest, 0},
+		{"/increment?by=two", http.StatusBadRequest, 0},
+	}
+
+	for _, step := range steps {
+		req, res := InitHTTP(POST, url+step.path, nil)
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, step.code; got != want {
+			t.Errorf("%s: got %v; want %v", step.path, got, want)
+		}
+
+		req, res = InitHTTP(GET, url, nil)
+		r.ServeHTTP(res, req)
+
+		var item models.Item
+		if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+			t.Fatal("Parse JSON Data Error")
+		}
+		if got, want := *item.Quantity, step.quantity; got != want {
+			t.Errorf("%s: got %v; want %v", step.path, got, want)
+		}
+	}
+
+	// Steps are recorded as adjustments
+	req, res = InitHTTP(GET, url+"/history", nil)
+	r.ServeHTTP(res, req)
+
+	var history []models.HistoryEntry
+	if err := json.Unmarshal(res.Body.Bytes(), &history); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := len(history), 5; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for _, entry := range history[1:] {
+		if got, want := entry.Operation, models.OperationAdjust; got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestIncrementNotFound(t *testing.T) {
+	r := Setup()
+
+	req, res := InitHTTP(POST, rootURL+"/not-a-real-ID/increment", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestPreviewSKUNormalization(t *testing.T) {
+	r := SetupWithConfig(Config{AdminAPIKey: "secret"})
+
+	// Create a catalog with case-variant SKUs
+	skus := []string{"abcd", "ABCD", "AbCd", "wxyz", "WXYZ", "EFGH"}
+	ids := make(map[string]models.ID)
+	for i, sku := range skus {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": fmt.Sprintf("Thing%d", i)})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		ids[sku] = models.ID(strings.TrimPrefix(res.Result().Header.Get("Location"), "/"))
+	}
+
+	// Preview the normalization
+	req, res := InitAdminHTTP(GET, "/api/admin/sku-normalization/preview", nil, "secret")
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	var report models.SKUNormalizationReport
+	if err := json.Unmarshal(res.Body.Bytes(), &report); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	want := models.SKUNormalizationReport{
+		Total:   6,
+		Changed: 3,
+		Collisions: []models.SKUCollision{
+			{
+				SKU:  "ABCD",
+				IDs:  []models.ID{ids["ABCD"], ids["AbCd"], ids["abcd"]},
+				SKUs: []models.SKU{"ABCD", "AbCd", "abcd"},
+			},
+			{
+				SKU:  "WXYZ",
+				IDs:  []models.ID{ids["WXYZ"], ids["wxyz"]},
+				SKUs: []models.SKU{"WXYZ", "wxyz"},
+			},
+		},
+	}
+	if got := report; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// The preview does not modify any SKUs
+	for sku, id := range ids {
+		req, res := InitHTTP(GET, rootURL+"/"+string(id), nil)
+		r.ServeHTTP(res, req)
+
+		var item models.Item
+		if err := json.Unmarshal(res.Body.Bytes(), &item); err != nil {
+			t.Fatal("Parse JSON Data Error")
+		}
+		if got, want := item.SKU, models.SKU(sku); got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}
+}
+
+func TestPreviewSKUNormalizationUnauthorized(t *testing.T) {
+	tests := map[string]struct {
+		config Config
+		key    string
+		code   int
+	}{
+		"missing key": {
+			config: Config{AdminAPIKey: "secret"},
+			key:    "",
+			code:   http.StatusUnauthorized,
+		},
+		"wrong key": {
+			config: Config{AdminAPIKey: "secret"},
+			key:    "guess",
+			code:   http.StatusForbidden,
+		},
+		"admin disabled": {
+			config: Config{},
+			key:    "secret",
+			code:   http.StatusForbidden,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := SetupWithConfig(test.config)
+
+			req, res := InitAdminHTTP(GET, "/api/admin/sku-normalization/preview", nil, test.key)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	for _, sku := range []string{"AAAAAAAA", "BBBBBBBB"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing"})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	// Fail to get an item
+	req, res := InitHTTP(GET, rootURL+"/not-a-real-ID", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNotFound; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// Scrape the metrics
+	req, res = InitHTTP(GET, "/metrics", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	body := res.Body.String()
+	for _, want := range []string{
+		`http_requests_total{method="POST",path="/api/items",status="201"} 2`,
+		`http_requests_total{method="GET",path="/api/items/{id}",status="404"} 1`,
+		`http_request_duration_seconds_count{method="POST",path="/api/items"} 2`,
+		`http_request_duration_seconds_count{method="GET",path="/api/items/{id}"} 1`,
+		`inventory_items 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("got %v; want %v", body, want)
+		}
+	}
+}
+
+func TestStrictQuantity(t *testing.T) {
+	defer func() { models.StrictQuantity = false }()
+
+	tests := map[string]struct {
+		strict  string
+		method  string
+		bodyMap map[string]interface{}
+		code    int
+	}{
+		"lenient create without quantity": {
+			strict:  "false",
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"},
+			code:    http.StatusCreated,
+		},
+		"lenient update without quantity": {
+			strict:  "false",
+			method:  PUT,
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"},
+			code:    http.StatusNoContent,
+		},
+		"strict create without quantity": {
+			strict:  "true",
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"},
+			code:    http.StatusBadRequest,
+		},
+		"strict create with null quantity": {
+			strict:  "true",
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2", "quantity": nil},
+			code:    http.StatusBadRequest,
+		},
+		"strict create with zero quantity": {
+			strict:  "true",
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2", "quantity": 0},
+			code:    http.StatusCreated,
+		},
+		"strict update without quantity": {
+			strict:  "true",
+			method:  PUT,
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"},
+			code:    http.StatusBadRequest,
+		},
+		"strict update with quantity": {
+			strict:  "true",
+			method:  PUT,
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 3},
+			code:    http.StatusNoContent,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup configures the Server from the environment
+			t.Setenv("STRICT_QUANTITY", test.strict)
+			r := Setup()
+
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 10})
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			url := rootURL
+			if test.method == PUT {
+				url += res.Header().Get("Location")
+			}
+			req, res = InitHTTP(test.method, url, test.bodyMap)
+			r.ServeHTTP(res, req)
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if test.code == http.StatusBadRequest {
+				if got, want := res.Header().Get("X-Error-Field"), "quantity"; got != want {
+					t.Errorf("got %v; want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUniqueNames(t *testing.T) {
+	defer func() { models.UniqueNames = false }()
+
+	tests := map[string]struct {
+		method  string
+		second  bool
+		bodyMap map[string]interface{}
+		code    int
+		field   string
+	}{
+		"create duplicate name": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "CCCCCCCC", "name": "Thing1"},
+			code:    http.StatusConflict,
+			field:   "name",
+		},
+		"create duplicate sku": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing3"},
+			code:    http.StatusConflict,
+			field:   "sku",
+		},
+		"create unique name": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "CCCCCCCC", "name": "Thing3"},
+			code:    http.StatusCreated,
+		},
+		"update to duplicate name": {
+			method:  PUT,
+			second:  true,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing1"},
+			code:    http.StatusConflict,
+			field:   "name",
+		},
+		"update keeping own name": {
+			method:  PUT,
+			second:  true,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2", "quantity": 1},
+			code:    http.StatusNoContent,
+		},
+		"update to unused name": {
+			method:  PUT,
+			second:  true,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing3"},
+			code:    http.StatusNoContent,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup configures the Server from the environment
+			r := Setup()
+			models.UniqueNames = true
+
+			// Create the items
+			var urls []string
+			for _, bodyMap := range []map[string]interface{}{
+				{"sku": "AAAAAAAA", "name": "Thing1"},
+				{"sku": "BBBBBBBB", "name": "Thing2"},
+			} {
+				req, res := InitHTTP(POST, rootURL, bodyMap)
+				r.ServeHTTP(res, req)
+
+				if got, want := res.Code, http.StatusCreated; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				urls = append(urls, rootURL+res.Result().Header.Get("Location"))
+			}
+
+			// Create a new item or update the second item
+			url := rootURL
+			if test.second {
+				url = urls[1]
+			}
+			req, res := InitHTTP(test.method, url, test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := res.Result().Header.Get("X-Error-Field"), test.field; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUniqueBarcodes(t *testing.T) {
+	defer func() { models.UniqueBarcodes = false }()
+
+	tests := map[string]struct {
+		method  string
+		second  bool
+		bodyMap map[string]interface{}
+		code    int
+		field   string
+	}{
+		"create duplicate barcode": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "CCCCCCCC", "name": "Thing3", "barcode": "4006381333931"},
+			code:    http.StatusConflict,
+			field:   "barcode",
+		},
+		"create without barcode": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "CCCCCCCC", "name": "Thing3"},
+			code:    http.StatusCreated,
+		},
+		"update to duplicate barcode": {
+			method:  PUT,
+			second:  true,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2", "barcode": "4006381333931"},
+			code:    http.StatusConflict,
+			field:   "barcode",
+		},
+		"update to unused barcode": {
+			method:  PUT,
+			second:  true,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2", "barcode": "9780201379624"},
+			code:    http.StatusNoContent,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Setup configures the Server from the environment
+			r := Setup()
+			models.UniqueBarcodes = true
+
+			// Create the items, only the first of which has a barcode
+			var urls []string
+			for _, bodyMap := range []map[string]interface{}{
+				{"sku": "AAAAAAAA", "name": "Thing1", "barcode": "4006381333931"},
+				{"sku": "BBBBBBBB", "name": "Thing2"},
+			} {
+				req, res := InitHTTP(POST, rootURL, bodyMap)
+				r.ServeHTTP(res, req)
+
+				if got, want := res.Code, http.StatusCreated; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				urls = append(urls, rootURL+res.Result().Header.Get("Location"))
+			}
+
+			// Create a new item or update the second item
+			url := rootURL
+			if test.second {
+				url = urls[1]
+			}
+			req, res := InitHTTP(test.method, url, test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := res.Result().Header.Get("X-Error-Field"), test.field; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUniqueNamesNotEnforced(t *testing.T) {
+	r := Setup()
+
+	for _, sku := range []string{"AAAAAAAA", "BBBBBBBB"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing1"})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}
+}
+
+// An envelope holds a page of Items as returned by GetItems with envelope=true.
+type envelope struct {
+	Data []models.Item `json:"data"`
+	Page models.Page   `json:"page"`
+}
+
+func TestGetItemsEnvelope(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	for i := 0; i < 5; i++ {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": fmt.Sprintf("AAAAAAA%d", i), "name": "Thing"})
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+	}
+
+	tests := map[string]struct {
+		url   string
+		count int
+		page  models.Page
+	}{
+		"first page": {
+			url:   rootURL + "?envelope=true&limit=2",
+			count: 2,
+			page:  models.Page{Limit: 2, Offset: 0, Total: 5, Next: rootURL + "?envelope=true&limit=2&offset=2"},
+		},
+		"middle page": {
+			url:   rootURL + "?envelope=true&limit=2&offset=2",
+			count: 2,
+			page:  models.Page{Limit: 2, Offset: 2, Total: 5, Next: rootURL + "?envelope=true&limit=2&offset=4", Prev: rootURL + "?envelope=true&limit=2&offset=0"},
+		},
+		"last page": {
+			url:   rootURL + "?envelope=true&limit=2&offset=4",
+			count: 1,
+			page:  models.Page{Limit: 2, Offset: 4, Total: 5, Prev: rootURL + "?envelope=true&limit=2&offset=2"},
+		},
+		"past the end": {
+			url:   rootURL + "?envelope=true&limit=2&offset=6",
+			count: 0,
+			page:  models.Page{Limit: 2, Offset: 6, Total: 5, Prev: rootURL + "?envelope=true&limit=2&offset=4"},
+		},
+		"default limit": {
+			url:   rootURL + "?envelope=true",
+			count: 5,
+			page:  models.Page{Limit: models.DEFAULT_PAGE_LIMIT, Offset: 0, Total: 5},
+		},
+		"filtered": {
+			url:   rootURL + "?envelope=true&tag=missing",
+			count: 0,
+			page:  models.Page{Limit: models.DEFAULT_PAGE_LIMIT, Offset: 0, Total: 0},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(GET, test.url, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+
+			var env envelope
+			if err := json.Unmarshal(res.Body.Bytes(), &env); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got, want := len(env.Data), test.count; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := env.Page, test.page; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemsEnvelopeAccept(t *testing.T) {
+	r := Setup()
+
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+	r.ServeHTTP(res, req)
+
+	// Request the envelope by media type
+	req, res = InitHTTP(GET, rootURL, nil)
+	req.Header.Set("Accept", "application/vnd.inventory.v2+json")
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got, want := res.Result().Header.Get("Content-Type"), ENVELOPE_MEDIA_TYPE; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	envelopeETag := res.Result().Header.Get("ETag")
+
+	var env envelope
+	if err := json.Unmarshal(res.Body.Bytes(), &env); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := len(env.Data), 1; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := env.Page.Total, 1; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// The bare array is still the default, with its own ETag
+	req, res = InitHTTP(GET, rootURL, nil)
+	r.ServeHTTP(res, req)
+
+	var items []models.Item
+	if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := len(items), 1; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := res.Result().Header.Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if res.Result().Header.Get("ETag") == envelopeETag {
+		t.Error("expected the envelope and the bare array to have different ETags")
+	}
+}
+
+func TestGetItemsPaginated(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	for i := 0; i < 5; i++ {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": fmt.Sprintf("AAAAAAA%d", i), "name": "Thing"})
+		r.ServeHTTP(res, req)
+	}
+
+	// Page through the bare array
+	seen := make(map[models.ID]bool)
+	for offset := 0; offset < 5; offset += 2 {
+		req, res := InitHTTP(GET, fmt.Sprintf("%s?limit=2&offset=%d", rootURL, offset), nil)
+		r.ServeHTTP(res, req)
+
+		var items []models.Item
+		if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+			t.Fatal("Parse JSON Data Error")
+		}
+		for _, item := range items {
+			if seen[item.ID] {
+				t.Errorf("item %v appeared on more than one page", item.ID)
+			}
+			seen[item.ID] = true
+		}
+	}
+	if got, want := len(seen), 5; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGetItemsAfter(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	for i := 0; i < 5; i++ {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": fmt.Sprintf("AAAAAAA%d", i), "name": "Thing"})
+		r.ServeHTTP(res, req)
+	}
+
+	// Page through with cursors, adding an item mid-scan
+	seen := make(map[models.ID]bool)
+	after, pages := "", 0
+	for {
+		req, res := InitHTTP(GET, fmt.Sprintf("%s?limit=2&after=%s", rootURL, after), nil)
+		r.ServeHTTP(res, req)
+		if got, want := res.Code, http.StatusOK; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+
+		var items []models.Item
+		if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+			t.Fatal("Parse JSON Data Error")
+		}
+		for _, item := range items {
+			if seen[item.ID] {
+				t.Errorf("item %v appeared on more than one page", item.ID)
+			}
+			seen[item.ID] = true
+		}
+
+		pages++
+		if pages == 1 {
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing"})
+			r.ServeHTTP(res, req)
+		}
+		if after = res.Header().Get("X-Next-Cursor"); after == "" {
+			break
+		}
+	}
+	if got, want := len(seen), 6; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if got, want := pages, 3; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGzip(t *testing.T) {
+	r := Setup()
+
+	// Create enough items for the list to exceed the threshold
+	for i := 0; i < 20; i++ {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": fmt.Sprintf("AAAAAA%02d", i), "name": "Thing", "description": "a thing worth compressing"})
+		r.ServeHTTP(res, req)
+	}
+
+	// Get the items compressed
+	req, res := InitHTTP(GET, rootURL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Header().Get("Content-Encoding"), "gzip"; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if got := res.Header().Get("Content-Length"); got != "" {
+		t.Errorf("got %v; want no Content-Length", got)
+	}
+	gz, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []models.Item
+	if err := json.NewDecoder(gz).Decode(&items); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := len(items), 20; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestGzipSkipped(t *testing.T) {
+	r := Setup()
+
+	// Create the items
+	var items []models.Item
+	for _, sku := range []string{"AAAAAAAA", "BBBBBBBB"} {
+		req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": "Thing"})
+		r.ServeHTTP(res, req)
+		var item models.Item
+		json.Unmarshal(res.Body.Bytes(), &item)
+		items = append(items, item)
+	}
+
+	tests := map[string]struct {
+		method   string
+		url      string
+		encoding string
+		code     int
+	}{
+		"small body":        {method: GET, url: rootURL + "/" + string(items[0].ID), encoding: "gzip", code: http.StatusOK},
+		"gzip not accepted": {method: GET, url: rootURL + "/" + string(items[0].ID), encoding: "gzip;q=0", code: http.StatusOK},
+		"no body":           {method: DELETE, url: rootURL + "/" + string(items[1].ID), encoding: "gzip", code: http.StatusNoContent},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, res := InitHTTP(test.method, test.url, nil)
+			req.Header.Set("Accept-Encoding", test.encoding)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got := res.Header().Get("Content-Encoding"); got != "" {
+				t.Errorf("got %v; want no Content-Encoding", got)
+			}
+		})
+	}
+}
+
+func TestGetItemsInvalidPagination(t *testing.T) {
+	tests := map[string]string{
+		"zero limit":         "?limit=0",
+		"negative limit":     "?limit=-1",
+		"limit too large":    fmt.Sprintf("?limit=%d", models.MAX_PAGE_LIMIT+1),
+		"malformed limit":    "?limit=ten",
+		"negative offset":    "?offset=-1",
+		"malformed offset":   "?offset=ten",
+		"malformed envelope": "?envelope=maybe",
+		"malformed cursor":   "?after=not-a-cursor",
+		"after and offset":   "?after=&offset=2",
+		"after and envelope": "?after=&envelope=true",
+	}
+
+	for name, query := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			req, res := InitHTTP(GET, rootURL+query, nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusBadRequest; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	events := make(chan models.Event, 10)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event models.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		events <- event
+	}))
+	defer hook.Close()
+
+	r := SetupWithConfig(Config{WebhookURL: hook.URL})
+
+	// Create, adjust, and delete an item
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing", "quantity": 5})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	location := res.Result().Header.Get("Location")
+	id := models.ID(location[1:])
+
+	req, res = InitHTTP(POST, rootURL+location+"/adjust", map[string]interface{}{"amount": -2})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	req, res = InitHTTP(DELETE, rootURL+location, nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	// Check that the events arrive in order
+	steps := []struct {
+		eventType models.EventType
+		quantity  int
+	}{
+		{models.EventItemCreated, 5},
+		{models.EventItemAdjusted, 3},
+		{models.EventItemDeleted, 0},
+	}
+	for _, step := range steps {
+		select {
+		case event := <-events:
+			if got, want := event.Type, step.eventType; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := event.ID, id; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if event.At.IsZero() {
+				t.Errorf("expected event to have a timestamp")
+			}
+			if step.eventType == models.EventItemDeleted {
+				if event.Item != nil {
+					t.Errorf("got %v; want %v", event.Item, nil)
+				}
+				continue
+			}
+			if event.Item == nil {
+				t.Fatalf("expected %v event to have an item", step.eventType)
+			}
+			if got, want := *event.Item.Quantity, step.quantity; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %v event", step.eventType)
+		}
+	}
+}
+
+func TestWebhookFailureDoesNotFailRequest(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer hook.Close()
+
+	r := SetupWithConfig(Config{WebhookURL: hook.URL})
+
+	req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing", "quantity": 5})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+func TestWebhookDeliver(t *testing.T) {
+	tests := map[string]struct {
+		codes    []int
+		attempts int
+		isError  bool
+	}{
+		"success":               {codes: []int{http.StatusOK}, attempts: 1, isError: false},
+		"retried after 5xx":     {codes: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusNoContent}, attempts: 3, isError: false},
+		"gives up after 5xx":    {codes: []int{http.StatusInternalServerError}, attempts: 3, isError: true},
+		"not retried after 4xx": {codes: []int{http.StatusBadRequest, http.StatusOK}, attempts: 1, isError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			attempts := 0
+			hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				code := test.codes[len(test.codes)-1]
+				if attempts < len(test.codes) {
+					code = test.codes[attempts]
+				}
+				attempts++
+				w.WriteHeader(code)
+			}))
+			defer hook.Close()
+
+			wh := &webhook{url: hook.URL, client: hook.Client(), retries: 2, backoff: time.Millisecond}
+			err := wh.deliver([]byte(`{}`))
+			if isError := err != nil; isError != test.isError {
+				t.Errorf("got %v; want %v", err, test.isError)
+			}
+			if got, want := attempts, test.attempts; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestItemSchema(t *testing.T) {
+	if itemSchemaErr != nil {
+		t.Fatal(itemSchemaErr)
+	}
+
+	var spec interface{}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatal(err)
+	}
+	properties, _ := lookup(spec, "components", "schemas", "ItemInput", "properties").(map[string]interface{})
+
+	// Every field of the schema must be a field of an Item
+	fields := make(map[string]bool)
+	itemType := reflect.TypeOf(models.Item{})
+	for i := 0; i < itemType.NumField(); i++ {
+		fields[strings.Split(itemType.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	for property := range properties {
+		if !fields[property] {
+			t.Errorf("got schema field %v; want a field of an Item", property)
+		}
+	}
+
+	// The published bounds must be those of the Item's own validators
+	tests := map[string]struct {
+		path []string
+		want float64
+	}{
+		"name":          {path: []string{"name", "maxLength"}, want: models.NAME_MAX_LEN},
+		"description":   {path: []string{"description", "maxLength"}, want: models.DESCRIPTION_MAX_LEN},
+		"images":        {path: []string{"images", "maxItems"}, want: models.MAX_IMAGES},
+		"supplier name": {path: []string{"name", "maxLength"}, want: models.SUPPLIER_NAME_MAX_LEN},
+		"supplier sku":  {path: []string{"sku", "maxLength"}, want: models.SUPPLIER_SKU_MAX_LEN},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var schema interface{} = properties
+			if strings.HasPrefix(name, "supplier") {
+				schema = lookup(spec, "components", "schemas", "Supplier", "properties")
+			}
+			if got, want := lookup(schema, test.path...), test.want; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	r := Setup()
+	req, res := InitHTTP(GET, "/openapi.json", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title   string `json:"title"`
+			Version string `json:"version"`
+		} `json:"info"`
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("Parse JSON Data Error: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("got %v; want %v", spec.OpenAPI, "3.x")
+	}
+	if spec.Info.Title == "" || spec.Info.Version == "" {
+		t.Errorf("got %v; want a title and version", spec.Info)
+	}
+
+	// Every $ref must point into the document
+	var doc interface{}
+	json.Unmarshal(res.Body.Bytes(), &doc)
+	var checkRefs func(v interface{})
+	checkRefs = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				var target interface{} = doc
+				for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+					obj, _ := target.(map[string]interface{})
+					target = obj[key]
+				}
+				if target == nil {
+					t.Errorf("got unresolved $ref %v", ref)
+				}
+			}
+			for _, child := range v {
+				checkRefs(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				checkRefs(child)
+			}
+		}
+	}
+	checkRefs(doc)
+
+	// The spec must describe exactly the registered routes
+	registered := map[string]bool{}
+	r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// The prefix of a subrouter is not itself a route
+			return nil
+		}
+		for _, method := range methods {
+			registered[method+" "+path] = true
+		}
+		return nil
+	})
+	described := map[string]bool{}
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			if method == "parameters" {
+				continue
+			}
+			var op struct {
+				Responses map[string]json.RawMessage `json:"responses"`
+			}
+			if err := json.Unmarshal(operation, &op); err != nil || len(op.Responses) == 0 {
+				t.Errorf("got %v %v without responses; want responses", method, path)
+			}
+			described[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	for route := range registered {
+		if !described[route] {
+			t.Errorf("got undescribed route %v; want it in the spec", route)
+		}
+	}
+	for route := range described {
+		if !registered[route] {
+			t.Errorf("got unregistered route %v in the spec; want it removed", route)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	defer func(build BuildInfo) { Build = build }(Build)
+
+	tests := map[string]struct {
+		build BuildInfo
+		want  BuildInfo
+	}{
+		"local build": {
+			build: Build,
+			want:  BuildInfo{Version: "dev", Commit: "unknown", Built: "unknown"},
+		},
+		"stamped build": {
+			build: BuildInfo{Version: "1.2.0", Commit: "0123abc", Built: "2022-01-10T18:38:38Z"},
+			want:  BuildInfo{Version: "1.2.0", Commit: "0123abc", Built: "2022-01-10T18:38:38Z"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			Build = test.build
+			r := Setup()
+			req, res := InitHTTP(GET, "/version", nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			var got BuildInfo
+			if err := json.Unmarshal(res.Body.Bytes(), &got); err != nil {
+				t.Fatal("Parse JSON Data Error")
+			}
+			if got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	tests := map[string]struct {
+		handler http.HandlerFunc
+		code    int
+		body    string
+	}{
+		"nil dereference": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var item *models.Item
+				_ = *item.Quantity
+			},
+			code: http.StatusInternalServerError,
+			body: `"internal server error"`,
+		},
+		"panic with a value": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				panic("oops")
+			},
+			code: http.StatusInternalServerError,
+			body: `"internal server error"`,
+		},
+		"no panic": {
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			code: http.StatusNoContent,
+			body: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := mux.NewRouter()
+			r.HandleFunc("/panic", test.handler).Methods(GET)
+			r.Use(Gzip, Recover)
+			req, res := InitHTTP(GET, "/panic", nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got, want := res.Body.String(), test.body; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRecoverStartedResponse(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("oops")
+	}).Methods(GET)
+	r.Use(Recover)
+	req, res := InitHTTP(GET, "/panic", nil)
+
+	defer func() {
+		if got, want := recover(), http.ErrAbortHandler; got != want {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}()
+	r.ServeHTTP(res, req)
+}
+
+func TestRequestID(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		echoed   bool
+		generate bool
+	}{
+		"provided":          {header: "abc-123", echoed: true},
+		"missing":           {header: "", generate: true},
+		"too long":          {header: strings.Repeat("a", REQUEST_ID_MAX_LEN+1), generate: true},
+		"control character": {header: "abc\x01123", generate: true},
+		"space":             {header: "abc 123", generate: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var fromContext string
+			r := mux.NewRouter()
+			r.HandleFunc("/id", func(w http.ResponseWriter, r *http.Request) {
+				fromContext = RequestIDFromContext(r.Context())
+				w.WriteHeader(http.StatusNoContent)
+			}).Methods(GET)
+			r.Use(RequestID)
+			req, res := InitHTTP(GET, "/id", nil)
+			if test.header != "" {
+				req.Header.Set(REQUEST_ID_HEADER, test.header)
+			}
+			r.ServeHTTP(res, req)
+
+			got := res.Header().Get(REQUEST_ID_HEADER)
+			if got != fromContext {
+				t.Errorf("got %v; want %v", got, fromContext)
+			}
+			if test.echoed && got != test.header {
+				t.Errorf("got %v; want %v", got, test.header)
+			}
+			if test.generate {
+				if code, err := (&models.Item{ID: models.ID(got)}).ValidateID(); err != nil {
+					t.Errorf("got %v; want a generated ID (%v)", got, code)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestIDLogged(t *testing.T) {
+	// Setting the default logger redirects the standard logger too, so restore both
+	defer func(logger *slog.Logger, w io.Writer, flags int) {
+		slog.SetDefault(logger)
+		log.SetOutput(w)
+		log.SetFlags(flags)
+	}(slog.Default(), log.Writer(), log.Flags())
+	var buf bytes.Buffer
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	r := mux.NewRouter()
+	r.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	}).Methods(GET)
+	r.Use(RequestID, Recover)
+	req, res := InitHTTP(GET, "/panic", nil)
+	req.Header.Set(REQUEST_ID_HEADER, "abc-123")
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("got %q; want a json record", buf.String())
+	}
+	want := map[string]interface{}{"msg": "panic serving request", "request_id": "abc-123", "method": GET, "path": "/panic"}
+	for key, want := range want {
+		if got := record[key]; got != want {
+			t.Errorf("%s: got %v; want %v", key, got, want)
+		}
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	tests := map[string]struct {
+		format  string
+		level   string
+		json    bool
+		debug   bool
+		info    bool
+		isError bool
+	}{
+		"default":        {json: false, info: true},
+		"text":           {format: "text", json: false, info: true},
+		"json":           {format: "JSON", json: true, info: true},
+		"debug":          {level: "debug", debug: true, info: true},
+		"warn":           {level: "WARN", info: false},
+		"unknown level":  {level: "verbose", isError: true},
+		"unknown format": {format: "xml", isError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("LOG_FORMAT", test.format)
+			t.Setenv("LOG_LEVEL", test.level)
+
+			var buf bytes.Buffer
+			logger, err := NewLogger(&buf)
+			if isError := err != nil; isError != test.isError {
+				t.Fatalf("got %v; want %v", err, test.isError)
+			}
+			if test.isError {
+				return
+			}
+
+			logger.Info("served request", "status", 200)
+			if got := buf.Len() > 0; got != test.info {
+				t.Errorf("got %v; want %v", got, test.info)
+			}
+			if test.info {
+				if got := json.Valid(buf.Bytes()); got != test.json {
+					t.Errorf("got %v; want %v", got, test.json)
+				}
+			}
+			if got := logger.Enabled(context.Background(), slog.LevelDebug); got != test.debug {
+				t.Errorf("got %v; want %v", got, test.debug)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	tests := map[string]struct {
+		method string
+		url    string
+		allow  string
+	}{
+		"item":         {method: POST, url: "/api/items/abc", allow: "GET, PUT, PATCH, DELETE"},
+		"items":        {method: PUT, url: "/api/items", allow: "GET, POST, DELETE"},
+		"adjust":       {method: GET, url: "/api/items/abc/adjust", allow: "POST"},
+		"metrics root": {method: POST, url: "/metrics", allow: "GET"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+			req, res := InitHTTP(test.method, test.url, nil)
+			r.ServeHTTP(res, req)
+
+			if res.Code != http.StatusMethodNotAllowed {
+				t.Errorf("got %v; want %v", res.Code, http.StatusMethodNotAllowed)
+			}
+			if got := res.Header().Get("Allow"); got != test.allow {
+				t.Errorf("got %v; want %v", got, test.allow)
+			}
+			if got := res.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("got %v; want %v", got, "application/json")
+			}
+			if res.Header().Get(REQUEST_ID_HEADER) == "" {
+				t.Errorf("got no %v header; want one", REQUEST_ID_HEADER)
+			}
+			var msg string
+			if err := json.Unmarshal(res.Body.Bytes(), &msg); err != nil {
+				t.Errorf("got %v; want a json error", res.Body.String())
+			}
+			want := fmt.Sprintf("method %s is not allowed; use %s", test.method, test.allow)
+			if msg != want {
+				t.Errorf("got %v; want %v", msg, want)
+			}
+		})
+	}
+}
+
+func TestValidationWarnings(t *testing.T) {
+	tests := map[string]struct {
+		method  string
+		second  bool
+		bodyMap map[string]interface{}
+		code    int
+		want    []string
+	}{
+		"create without warnings": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "CCCCCCCC", "name": "Thing3", "description": "A thing", "price": map[string]interface{}{"amount": 15.00, "currency": "CAD"}},
+			code:    http.StatusCreated,
+		},
+		"create with warnings": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "CCCCCCCC", "name": "Thing3", "price": map[string]interface{}{"amount": 0, "currency": "CAD"}},
+			code:    http.StatusCreated,
+			want:    []string{"price is 0.00", "description is empty"},
+		},
+		"create high quantity": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "CCCCCCCC", "name": "Thing3", "description": "A thing", "quantity": models.QUANTITY_WARN_THRESHOLD + 1},
+			code:    http.StatusCreated,
+			want:    []string{fmt.Sprintf("quantity is above %d", models.QUANTITY_WARN_THRESHOLD)},
+		},
+		"create conflict": {
+			method:  POST,
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing3"},
+			code:    http.StatusConflict,
+		},
+		"update with warnings": {
+			method:  PUT,
+			second:  true,
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"},
+			code:    http.StatusNoContent,
+			want:    []string{"description is empty"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the items
+			var urls []string
+			for _, bodyMap := range []map[string]interface{}{
+				{"sku": "AAAAAAAA", "name": "Thing1"},
+				{"sku": "BBBBBBBB", "name": "Thing2"},
+			} {
+				req, res := InitHTTP(POST, rootURL, bodyMap)
+				r.ServeHTTP(res, req)
+
+				if got, want := res.Code, http.StatusCreated; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				urls = append(urls, rootURL+res.Result().Header.Get("Location"))
+			}
+
+			// Create a new item or update the second item
+			url := rootURL
+			if test.second {
+				url = urls[1]
+			}
+			req, res := InitHTTP(test.method, url, test.bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+			if got := res.Result().Header.Values(VALIDATION_WARNINGS_HEADER); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWebhookLowStock(t *testing.T) {
+	events := make(chan models.Event, 20)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event models.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Error(err)
+		}
+		events <- event
+	}))
+	defer hook.Close()
+
+	r := SetupWithConfig(Config{WebhookURL: hook.URL})
+
+	item := map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing", "quantity": 10, "reorder_point": 5}
+	req, res := InitHTTP(POST, rootURL, item)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusCreated; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	location := res.Result().Header.Get("Location")
+
+	// Take the item down past its reorder point and back, re-saving it while its stock is low
+	changes := []struct {
+		method  string
+		path    string
+		bodyMap map[string]interface{}
+	}{
+		{POST, "/adjust", map[string]interface{}{"amount": -3}},
+		{POST, "/adjust", map[string]interface{}{"amount": -3}},
+		{PUT, "", map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing", "quantity": 4, "reorder_point": 5}},
+		{POST, "/decrement", nil},
+		{PUT, "", map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing", "quantity": 10, "reorder_point": 5}},
+		{PATCH, "", map[string]interface{}{"quantity": 2}},
+		{DELETE, "", nil},
+	}
+	for _, change := range changes {
+		req, res := InitHTTP(change.method, rootURL+location+change.path, change.bodyMap)
+		if change.method == PATCH {
+			req.Header.Set("Content-Type", models.MERGE_PATCH_MEDIA_TYPE)
+		}
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusNoContent; got != want {
+			t.Fatalf("%v %v: got %v; want %v", change.method, change.path, got, want)
+		}
+	}
+
+	// Check that the item's stock is reported low only when it falls to its reorder point
+	want := []models.EventType{
+		models.EventItemCreated,
+		models.EventItemAdjusted,
+		models.EventItemAdjusted, models.EventItemLowStock,
+		models.EventItemUpdated,
+		models.EventItemAdjusted,
+		models.EventItemUpdated,
+		models.EventItemUpdated, models.EventItemLowStock,
+		models.EventItemDeleted,
+	}
+	for _, eventType := range want {
+		select {
+		case event := <-events:
+			if got := event.Type; got != eventType {
+				t.Errorf("got %v; want %v", got, eventType)
+			}
+			if event.Type == models.EventItemLowStock && (event.Item == nil || !event.Item.NeedsReorder()) {
+				t.Errorf("got %v; want an item at its reorder point", event.Item)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %v event", eventType)
+		}
+	}
+}
+
+func TestStreamItems(t *testing.T) {
+	tests := map[string]struct {
+		skus []string
+	}{
+		"empty":    {skus: []string{}},
+		"single":   {skus: []string{"AAAAAAAA"}},
+		"multiple": {skus: []string{"AAAAAAAA", "BBBBBBBB", "CCCCCCCC"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			// Create the items, and one that is deleted
+			for _, sku := range append(test.skus, "DELETED1") {
+				req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": sku, "name": sku, "quantity": 2})
+				r.ServeHTTP(res, req)
+
+				if got, want := res.Code, http.StatusCreated; got != want {
+					t.Fatalf("got %v; want %v", got, want)
+				}
+				if sku == "DELETED1" {
+					req, res = InitHTTP(DELETE, rootURL+res.Result().Header.Get("Location"), nil)
+					r.ServeHTTP(res, req)
+				}
+			}
+
+			req, res := InitHTTP(GET, rootURL+"/stream", nil)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusOK; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			if got, want := res.Result().Header.Get("Content-Type"), NDJSON_MEDIA_TYPE; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+
+			// Check there is one item per line, in the order they were added
+			skus := []string{}
+			scanner := bufio.NewScanner(res.Body)
+			for scanner.Scan() {
+				var item models.Item
+				if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+					t.Fatalf("got %v; want an item per line", scanner.Text())
+				}
+				if got, want := item.Available, 2; got != want {
+					t.Errorf("got %v; want %v", got, want)
+				}
+				skus = append(skus, string(item.SKU))
+			}
+			if !reflect.DeepEqual(skus, test.skus) {
+				t.Errorf("got %v; want %v", skus, test.skus)
+			}
+		})
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	tests := map[string]struct {
+		name string
+		want string
+	}{
+		"no underscores": {name: "sku", want: "sku"},
+		"two words":      {name: "date_added", want: "dateAdded"},
+		"three words":    {name: "last_updated_at", want: "lastUpdatedAt"},
+		"uppercase word": {name: "price_CAD", want: "priceCAD"},
+		"trailing":       {name: "name_", want: "name"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := camelCase(test.name); got != test.want {
+				t.Errorf("got %v; want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCamelCaseJSON(t *testing.T) {
+	tests := map[string]struct {
+		body    string
+		want    string
+		isError bool
+	}{
+		"object": {
+			body: `{"sku":"A_B","cost_CAD":1.50,"reorder_point":null}` + "\n",
+			want: `{"sku":"A_B","costCAD":1.50,"reorderPoint":null}` + "\n",
+		},
+		"nested": {
+			body: `[{"page":{"next_url":"/items?a_b=1"},"data":[{"image_url":"x","tags":["a_b"]}]}]`,
+			want: `[{"page":{"nextUrl":"/items?a_b=1"},"data":[{"imageUrl":"x","tags":["a_b"]}]}]` + "\n",
+		},
+		"ndjson": {
+			body: `{"old_quantity":1}` + "\n" + `{"new_quantity":true}` + "\n",
+			want: `{"oldQuantity":1}` + "\n" + `{"newQuantity":true}` + "\n",
+		},
+		"string":    {body: `"name_taken"` + "\n", want: `"name_taken"` + "\n"},
+		"empty":     {body: "", want: ""},
+		"truncated": {body: `{"old_quantity":[1,`, isError: true},
+		"not json":  {body: `not_json`, isError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := camelCaseJSON([]byte(test.body))
+			if gotErr := err != nil; gotErr != test.isError {
+				t.Fatalf("got %v; want error %v", err, test.isError)
+			}
+			if !test.isError && string(got) != test.want {
+				t.Errorf("got %v; want %v", string(got), test.want)
+			}
+		})
+	}
+}
+
+func TestNaming(t *testing.T) {
+	tests := map[string]struct {
+		query   string
+		code    int
+		present []string
+		absent  []string
+	}{
+		"default": {
+			query:   "",
+			code:    http.StatusOK,
+			present: []string{`"cost_CAD"`, `"reorder_point"`, `"image_url"`},
+			absent:  []string{`"costCAD"`},
+		},
+		"snake": {
+			query:   "?naming=snake",
+			code:    http.StatusOK,
+			present: []string{`"cost_CAD"`, `"reorder_point"`, `"image_url"`},
+			absent:  []string{`"costCAD"`},
+		},
+		"camel": {
+			query:   "?naming=camel",
+			code:    http.StatusOK,
+			present: []string{`"costCAD"`, `"reorderPoint"`, `"imageUrl"`, `"AB_12345"`},
+			absent:  []string{`"cost_CAD"`, `"reorder_point"`, `"image_url"`},
+		},
+		"unknown": {
+			query: "?naming=kebab",
+			code:  http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			bodyMap := map[string]interface{}{
+				"sku":           "AB_12345",
+				"name":          "Thing",
+				"cost_CAD":      1.5,
+				"reorder_point": 2,
+				"image_url":     "https://cdn.example.com/thing.png",
+			}
+			req, res := InitHTTP(POST, rootURL, bodyMap)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			location := res.Result().Header.Get("Location")
+
+			for _, url := range []string{rootURL + location, rootURL, rootURL + "/stream"} {
+				req, res = InitHTTP(GET, url+test.query, nil)
+				r.ServeHTTP(res, req)
+
+				if got, want := res.Code, test.code; got != want {
+					t.Errorf("%v: got %v; want %v", url, got, want)
+				}
+				body := res.Body.String()
+				for _, key := range test.present {
+					if !strings.Contains(body, key) {
+						t.Errorf("%v: got %v; want it to contain %v", url, body, key)
+					}
+				}
+				for _, key := range test.absent {
+					if strings.Contains(body, key) {
+						t.Errorf("%v: got %v; want it not to contain %v", url, body, key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRequestContentType(t *testing.T) {
+	tests := map[string]struct {
+		method      string
+		path        string
+		contentType string
+		bodyMap     map[string]interface{}
+		code        int
+	}{
+		"create json": {
+			method: POST, contentType: "application/json",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusCreated,
+		},
+		"create json with charset": {
+			method: POST, contentType: "application/json; charset=utf-8",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusCreated,
+		},
+		"create json in uppercase": {
+			method: POST, contentType: "Application/JSON",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusCreated,
+		},
+		"create missing content type": {
+			method: POST, contentType: "",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusUnsupportedMediaType,
+		},
+		"create form": {
+			method: POST, contentType: "application/x-www-form-urlencoded",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusUnsupportedMediaType,
+		},
+		"create xml": {
+			method: POST, contentType: "application/xml",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusUnsupportedMediaType,
+		},
+		"create malformed content type": {
+			method: POST, contentType: "application/json; charset",
+			bodyMap: map[string]interface{}{"sku": "BBBBBBBB", "name": "Thing2"}, code: http.StatusUnsupportedMediaType,
+		},
+		"update missing content type": {
+			method: PUT, path: "/item", contentType: "",
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}, code: http.StatusUnsupportedMediaType,
+		},
+		"update wrong content type": {
+			method: PUT, path: "/item", contentType: "text/plain",
+			bodyMap: map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"}, code: http.StatusUnsupportedMediaType,
+		},
+		"adjust wrong content type": {
+			method: POST, path: "/item/adjust", contentType: "text/plain",
+			bodyMap: map[string]interface{}{"amount": 1}, code: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := Setup()
+
+			req, res := InitHTTP(POST, rootURL, map[string]interface{}{"sku": "AAAAAAAA", "name": "Thing1"})
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, http.StatusCreated; got != want {
+				t.Fatalf("got %v; want %v", got, want)
+			}
+			url := rootURL + strings.Replace(test.path, "/item", res.Result().Header.Get("Location"), 1)
+
+			req, res = InitHTTP(test.method, url, test.bodyMap)
+			req.Header.Set("Content-Type", test.contentType)
+			r.ServeHTTP(res, req)
+
+			if got, want := res.Code, test.code; got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetItemStats(t *testing.T) {
+	r := Setup()
+
+	// Check the statistics of an empty inventory
+	req, res := InitHTTP(GET, rootURL+"/stats", nil)
+	r.ServeHTTP(res, req)
+
+	var stats models.ItemStats
+	if err := json.Unmarshal(res.Body.Bytes(), &stats); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	if got, want := stats, (models.ItemStats{Currency: models.DefaultCurrency}); got != want {
+		t.Errorf("got %v; want %v", got, want)
+	}
+
+	// Create the items, and one that is deleted
+	bodyMaps := []map[string]interface{}{
+		{"sku": "AAAAAAAA", "name": "Thing1", "quantity": 4, "price": map[string]interface{}{"amount": 2.5, "currency": "CAD"}},
+		{"sku": "BBBBBBBB", "name": "Thing2", "quantity": 0, "price": map[string]interface{}{"amount": 100, "currency": "CAD"}},
+		{"sku": "CCCCCCCC", "name": "Thing3", "quantity": 3, "price": map[string]interface{}{"amount": 10, "currency": "USD"}},
+		{"sku": "DDDDDDDD", "name": "Thing4", "quantity": 6},
+		{"sku": "EEEEEEEE", "name": "Thing5", "quantity": 50, "price": map[string]interface{}{"amount": 1, "currency": "CAD"}},
+	}
+	var locations []string
+	for _, bodyMap := range bodyMaps {
+		req, res := InitHTTP(POST, rootURL, bodyMap)
+		r.ServeHTTP(res, req)
+
+		if got, want := res.Code, http.StatusCreated; got != want {
+			t.Fatalf("got %v; want %v", got, want)
+		}
+		locations = append(locations, res.Result().Header.Get("Location"))
+	}
+	req, res = InitHTTP(DELETE, rootURL+locations[4], nil)
+	r.ServeHTTP(res, req)
+
+	// Reserve all of the fourth item's stock
+	req, res = InitHTTP(POST, rootURL+locations[3]+"/reserve", map[string]interface{}{"amount": 6})
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusNoContent; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+
+	req, res = InitHTTP(GET, rootURL+"/stats", nil)
+	r.ServeHTTP(res, req)
+
+	if got, want := res.Code, http.StatusOK; got != want {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &stats); err != nil {
+		t.Fatal("Parse JSON Data Error")
+	}
+	want := models.ItemStats{Count: 4, TotalUnits: 13, TotalValue: 10, Currency: "CAD", OutOfStock: 2}
+	if stats != want {
+		t.Errorf("got %v; want %v", stats, want)
+	}
+}
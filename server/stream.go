@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lbisceglia/shopify/models"
+)
+
+// STREAM_FLUSH_INTERVAL is the number of Items written to a stream between flushes to the client.
+const STREAM_FLUSH_INTERVAL = 100
+
+// ENVELOPE_MEDIA_TYPE is the media type of a paginated envelope of Items.
+const ENVELOPE_MEDIA_TYPE = "application/vnd.inventory.v2+json"
+
+// NDJSON_MEDIA_TYPE is the media type of newline-delimited json, one Item per line.
+const NDJSON_MEDIA_TYPE = "application/x-ndjson"
+
+// An itemStream writes Items to a response as a json array, one at a time, or as newline-delimited json.
+// The array may be wrapped in an envelope along with a description of its page.
+// The response is only started once the first Item is written or the stream is closed,
+// so that an error before then can still be reported with an error status.
+type itemStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	started bool
+	count   int
+	prefix  string
+	suffix  string
+	ndjson  bool
+}
+
+// newItemStream creates a stream that writes to the response.
+func newItemStream(w http.ResponseWriter) *itemStream {
+	flusher, _ := w.(http.Flusher)
+	return &itemStream{w: w, flusher: flusher}
+}
+
+// newNDJSONStream creates a stream that writes to the response as newline-delimited json, rather than as a json array.
+func newNDJSONStream(w http.ResponseWriter) *itemStream {
+	s := newItemStream(w)
+	s.ndjson = true
+	return s
+}
+
+// Envelope wraps the stream's json array in an envelope, as the data of the given page.
+// It must be called before any Item is written.
+func (s *itemStream) Envelope(page models.Page) error {
+	b, err := json.Marshal(page)
+	if err != nil {
+		return err
+	}
+	s.prefix = `{"data":`
+	s.suffix = `,"page":` + string(b) + `}`
+	return nil
+}
+
+// Started returns true if the response has been started, false otherwise.
+func (s *itemStream) Started() bool {
+	return s.started
+}
+
+// Write writes an Item to the stream, starting the response if it has not yet been started.
+// The stream is flushed to the client every STREAM_FLUSH_INTERVAL Items.
+func (s *itemStream) Write(item *models.Item) error {
+	item.ComputeDerived()
+	b, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	sep := ","
+	if !s.started {
+		s.start()
+		sep = "["
+	}
+	if s.ndjson {
+		b = append(b, '\n')
+	} else if _, err := s.w.Write([]byte(sep)); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(b); err != nil {
+		return err
+	}
+
+	s.count++
+	if s.count%STREAM_FLUSH_INTERVAL == 0 && s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// Close ends the json array and any envelope, starting the response if no Items were written.
+// Newline-delimited json has nothing to end, so an empty stream is an empty response.
+func (s *itemStream) Close() error {
+	if s.ndjson {
+		if !s.started {
+			s.start()
+		}
+		return nil
+	}
+	end := "]"
+	if !s.started {
+		s.start()
+		end = "[]"
+	}
+	_, err := s.w.Write([]byte(end + s.suffix + "\n"))
+	return err
+}
+
+// start writes the response's 200 OK status and opens any envelope.
+func (s *itemStream) start() {
+	s.w.WriteHeader(http.StatusOK)
+	s.started = true
+	if s.prefix != "" {
+		s.w.Write([]byte(s.prefix))
+	}
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// A BuildInfo identifies a build of the server.
+type BuildInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Built   string `json:"built"`
+}
+
+// Build identifies the build of the server that is running.
+// main sets it from values injected at build time; local builds are "dev" and "unknown".
+var Build = BuildInfo{Version: "dev", Commit: "unknown", Built: "unknown"}
+
+// Version responds with the version, commit, and build time of the running server,
+// e.g. to confirm that a deploy rolled out.
+//
+// Returns the build information and a 200 OK.
+func (s *Server) Version(w http.ResponseWriter, r *http.Request) {
+	s.setHeader(w)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(Build); err != nil {
+		requestLog(r.Context()).Error("cannot write response", "error", err)
+	}
+}
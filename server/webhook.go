@@ -0,0 +1,116 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/lbisceglia/shopify/models"
+)
+
+// WEBHOOK_QUEUE_SIZE is the number of events that may be waiting to be sent to the webhook.
+// Events raised while the queue is full are dropped, so that a slow webhook never holds up a request.
+const WEBHOOK_QUEUE_SIZE = 100
+
+// WEBHOOK_MAX_RETRIES is the number of times an event is resent if the webhook responds with a 5xx
+// or cannot be reached.
+const WEBHOOK_MAX_RETRIES = 3
+
+// WEBHOOK_BACKOFF is how long to wait before resending an event to the webhook.
+// Each further retry waits twice as long as the one before.
+const WEBHOOK_BACKOFF = 500 * time.Millisecond
+
+// WEBHOOK_TIMEOUT is how long to wait for the webhook to respond to a single event.
+const WEBHOOK_TIMEOUT = 5 * time.Second
+
+// A webhook sends Events to a URL in the background.
+// Events are sent one at a time, in the order they were raised, by a single worker.
+type webhook struct {
+	url     string
+	client  *http.Client
+	queue   chan queuedEvent
+	retries int
+	backoff time.Duration
+}
+
+// A queuedEvent is an Event waiting to be sent, along with its json encoding.
+// Events are encoded when they are raised, so that later changes to the Item do not leak into them.
+type queuedEvent struct {
+	event models.Event
+	body  []byte
+}
+
+// newWebhook creates a webhook that sends Events to the URL and starts its worker.
+// Returns nil if the URL is empty, i.e. if no webhook is configured.
+func newWebhook(url string) *webhook {
+	if url == "" {
+		return nil
+	}
+	wh := &webhook{
+		url:     url,
+		client:  &http.Client{Timeout: WEBHOOK_TIMEOUT},
+		queue:   make(chan queuedEvent, WEBHOOK_QUEUE_SIZE),
+		retries: WEBHOOK_MAX_RETRIES,
+		backoff: WEBHOOK_BACKOFF,
+	}
+	go wh.run()
+	return wh
+}
+
+// send queues the Event to be sent to the webhook. It never blocks;
+// if the Event cannot be encoded or the queue is full, the Event is logged and dropped.
+func (wh *webhook) send(event models.Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		slog.Warn("webhook: dropping event", "event", event.Type, "item_id", event.ID, "error", err)
+		return
+	}
+	select {
+	case wh.queue <- queuedEvent{event: event, body: body}:
+	default:
+		slog.Warn("webhook: queue is full; dropping event", "event", event.Type, "item_id", event.ID)
+	}
+}
+
+// run sends the queued Events as they arrive, logging those that cannot be delivered.
+func (wh *webhook) run() {
+	for q := range wh.queue {
+		if err := wh.deliver(q.body); err != nil {
+			slog.Error("webhook: cannot send event", "event", q.event.Type, "item_id", q.event.ID, "error", err)
+		}
+	}
+}
+
+// deliver posts an encoded Event to the webhook, retrying up to wh.retries times with exponential backoff
+// if the webhook responds with a 5xx or cannot be reached. Other responses are not retried.
+// Returns nil if the webhook responds with a 2xx, the error of the last attempt otherwise.
+func (wh *webhook) deliver(body []byte) error {
+	backoff := wh.backoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := wh.post(body)
+		if err == nil || attempt >= wh.retries || !retryable {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post makes a single attempt to post an encoded Event to the webhook.
+// Returns false and nil if the webhook responds with a 2xx.
+// Returns true and an error if the webhook responds with a 5xx or cannot be reached, false and an error otherwise.
+func (wh *webhook) post(body []byte) (bool, error) {
+	res, err := wh.client.Post(wh.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	return res.StatusCode >= 500, fmt.Errorf("webhook responded with %s", res.Status)
+}